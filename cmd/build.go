@@ -1,40 +1,77 @@
 /*
 Copyright © 2024 NAME HERE <EMAIL ADDRESS>
-
 */
 package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/aden-q/monkey/internal/compiler"
 	"github.com/spf13/cobra"
 )
 
+// bytecodeExt is the file extension of compiled Monkey programs
+const bytecodeExt = ".mkc"
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
-	Use:   "build",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("build called")
-	},
+	Use:   "build <file>",
+	Short: "Compile a Monkey source file to bytecode",
+	Long: `Build compiles a Monkey source file to bytecode and writes it to disk.
+The output can be executed by the VM with the run command. The compiler supports
+the language except try/catch/finally and throw, and closures cannot assign to the
+variables they capture from an enclosing function. For example:
+
+  monkey build foo.mk -o foo.mkc
+  monkey run foo.mkc`,
+	Args: cobra.ExactArgs(1),
+	Run:  buildFile,
 }
 
-func init() {
-	rootCmd.AddCommand(buildCmd)
+func buildFile(cmd *cobra.Command, args []string) {
+	path := args[0]
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flag error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(path, filepath.Ext(path)) + bytecodeExt
+	}
 
-	// Here you will define your flags and configuration settings.
+	src, err := readSource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// buildCmd.PersistentFlags().String("foo", "", "A help for foo")
+	program := mustParse(path, string(src))
+
+	c := compiler.NewWithState(newSymbolTable(), nil)
+	if err := c.Compile(program); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: compile error: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	data, err := c.Bytecode().MarshalBinary()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: compile error: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "write error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// buildCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	buildCmd.Flags().StringP("output", "o", "", "output file (default is the input file with the .mkc extension)")
 }
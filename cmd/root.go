@@ -47,7 +47,8 @@ func run(cmd *cobra.Command, args []string) {
 	}
 
 	r := repl.New(repl.Config{
-		MaxHistory: config.MaxHistory,
+		MaxHistory:  config.MaxHistory,
+		HistoryFile: config.HistoryFile,
 	})
 
 	r.Start(os.Stdin, os.Stdout, user.Username)
@@ -1,40 +1,155 @@
 /*
 Copyright © 2024 NAME HERE <EMAIL ADDRESS>
-
 */
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/aden-q/monkey/internal/ast"
+	"github.com/aden-q/monkey/internal/compiler"
+	"github.com/aden-q/monkey/internal/diagnostic"
+	"github.com/aden-q/monkey/internal/evaluator"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+	"github.com/aden-q/monkey/internal/vm"
 	"github.com/spf13/cobra"
 )
 
+// stdinPath is the special file path that makes run read the program from stdin
+const stdinPath = "-"
+
+// argsIdentifier is the name the program arguments are bound to
+const argsIdentifier = "args"
+
 // runCmd represents the run command
 var runCmd = &cobra.Command{
-	Use:   "run",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Use:   "run <file> [args...]",
+	Short: "Run a Monkey source file",
+	Long: `Run reads a whole Monkey source file, parses it and evaluates it.
+Use - as the file to read the program from stdin. Bytecode files produced
+by the build command are detected automatically and executed by the VM.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("run called")
-	},
+Any arguments following the file are forwarded to the program
+as an array of strings bound to the args identifier. For example:
+
+  monkey run examples/hello.mk alice bob`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runFile,
 }
 
-func init() {
-	rootCmd.AddCommand(runCmd)
+func runFile(cmd *cobra.Command, args []string) {
+	path, programArgs := args[0], args[1:]
+
+	src, err := readSource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if compiler.IsBytecode(src) {
+		// bytecode has no source text to show the failing part of
+		if err := runBytecode(cmd.OutOrStdout(), src, programArgs); err != nil {
+			// files failing to decode or validate are told apart from errors raised by the program
+			kind := "runtime error"
+			if errors.Is(err, compiler.ErrInvalidBytecode) {
+				kind = "invalid bytecode file"
+			}
+
+			fmt.Fprintf(os.Stderr, "%s: %s: %v\n", path, kind, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if err := evalSource(cmd.OutOrStdout(), path, string(src), programArgs); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: runtime error: %v\n", path, err)
+		diagnostic.WriteSnippet(os.Stderr, "\t", string(src), err)
+		diagnostic.WriteStackTrace(os.Stderr, "\t", err)
+		os.Exit(1)
+	}
+}
+
+// evalSource parses a program and evaluates it with the tree-walking evaluator
+func evalSource(out io.Writer, path string, text string, programArgs []string) error {
+	program := mustParse(path, text)
+
+	env := object.NewEnvironment()
+	env.Set(argsIdentifier, newArgsArray(programArgs))
+
+	_, err := evaluator.NewWithOutput(env, out).Eval(program)
+
+	return err
+}
+
+// runBytecode decodes a compiled program and executes it with the VM
+func runBytecode(out io.Writer, src []byte, programArgs []string) error {
+	bytecode := &compiler.Bytecode{}
+	if err := bytecode.UnmarshalBinary(src); err != nil {
+		return err
+	}
 
-	// Here you will define your flags and configuration settings.
+	globals := vm.NewGlobals()
+	globals[newSymbolTable().Define(argsIdentifier).Index] = newArgsArray(programArgs)
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// runCmd.PersistentFlags().String("foo", "", "A help for foo")
+	_, err := vm.NewWithOutput(bytecode, globals, out).Run()
+
+	return err
+}
+
+// mustParse parses a program, it reports the parser errors and exits on failure
+func mustParse(path string, text string) *ast.Program {
+	p := parser.New(lexer.New())
+	program, errs := p.ParseProgram(text)
+	if len(errs) != 0 {
+		fmt.Fprintf(os.Stderr, "%s: parser errors:\n", path)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "\t%v\n", err)
+			diagnostic.WriteSnippet(os.Stderr, "\t", text, err)
+		}
+
+		os.Exit(1)
+	}
+
+	return program
+}
+
+// newSymbolTable creates the global symbol table shared by compiled programs,
+// the globals predeclared here are populated by the run command
+func newSymbolTable() *compiler.SymbolTable {
+	symbolTable := compiler.NewSymbolTable()
+	symbolTable.Define(argsIdentifier)
+
+	return symbolTable
+}
+
+// readSource reads the whole program text from a file, or from stdin when the path is -
+func readSource(path string) ([]byte, error) {
+	if path == stdinPath {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(path)
+}
+
+// newArgsArray converts command line arguments to an array of string objects
+func newArgsArray(args []string) *object.Array {
+	elements := make([]object.Object, 0, len(args))
+	for _, arg := range args {
+		elements = append(elements, object.NewString(arg))
+	}
+
+	return object.NewArray(elements...)
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// runCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	// stop parsing flags after the file so that flags are forwarded to the program
+	runCmd.Flags().SetInterspersed(false)
 }
@@ -0,0 +1,16 @@
+let greet = fn(name) {
+  print("Hello, " + name + "!");
+};
+
+let greetAll = fn(names, i) {
+  if (i < len(names)) {
+    greet(names[i]);
+    greetAll(names, i + 1);
+  };
+};
+
+if (len(args) > 0) {
+  greetAll(args, 0);
+} else {
+  greet("world");
+};
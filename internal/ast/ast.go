@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/aden-q/monkey/internal/token"
@@ -10,24 +11,39 @@ import (
 var _ Node = (*Program)(nil)
 var _ Expression = (*IdentifierExpression)(nil)
 var _ Expression = (*IntegerExpression)(nil)
+var _ Expression = (*FloatExpression)(nil)
 var _ Expression = (*BooleanExpression)(nil)
+var _ Expression = (*NilExpression)(nil)
 var _ Expression = (*StringExpression)(nil)
+var _ Expression = (*InterpolatedStringExpression)(nil)
 var _ Expression = (*ArrayExpression)(nil)
 var _ Expression = (*HashExpression)(nil)
 var _ Expression = (*IndexExpression)(nil)
+var _ Expression = (*SliceExpression)(nil)
 var _ Expression = (*IfExpression)(nil)
 var _ Expression = (*FuncExpression)(nil)
 var _ Expression = (*CallExpression)(nil)
 var _ Expression = (*PrefixExpression)(nil)
 var _ Expression = (*InfixExpression)(nil)
+var _ Expression = (*AssignExpression)(nil)
 var _ Statement = (*LetStatement)(nil)
 var _ Statement = (*ReturnStatement)(nil)
 var _ Statement = (*ExpressionStatement)(nil)
 var _ Statement = (*BlockStatement)(nil)
+var _ Statement = (*WhileStatement)(nil)
+var _ Statement = (*ForStatement)(nil)
+var _ Statement = (*BreakStatement)(nil)
+var _ Statement = (*ContinueStatement)(nil)
+var _ Statement = (*TryStatement)(nil)
+var _ Statement = (*ThrowStatement)(nil)
 
 // Node is a common interface for nodes in AST
 type Node interface {
 	TokenLiteral() string
+	// Pos returns the position of the first character of the node
+	Pos() token.Position
+	// End returns the position immediately after the node
+	End() token.Position
 	// for debug purpose only
 	String() string
 }
@@ -58,6 +74,22 @@ func (p *Program) TokenLiteral() string {
 	return p.Statements[0].TokenLiteral()
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) == 0 {
+		return token.Position{}
+	}
+
+	return p.Statements[0].Pos()
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) == 0 {
+		return token.Position{}
+	}
+
+	return p.Statements[len(p.Statements)-1].End()
+}
+
 func (p *Program) String() string {
 	builder := strings.Builder{}
 
@@ -81,6 +113,19 @@ func NewProgram(statements ...Statement) *Program {
 	}
 }
 
+// after returns the position immediately after a single character delimiter
+func after(pos token.Position) token.Position {
+	if !pos.IsValid() {
+		return pos
+	}
+
+	return token.Position{
+		Offset: pos.Offset + 1,
+		Line:   pos.Line,
+		Column: pos.Column + 1,
+	}
+}
+
 // -------------- Expressions -------------------
 
 // IdentifierExpression implements the Expression interface
@@ -96,6 +141,14 @@ func (ie *IdentifierExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IdentifierExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
+func (ie *IdentifierExpression) End() token.Position {
+	return ie.Token.End
+}
+
 func (ie *IdentifierExpression) String() string {
 	return ie.Value
 }
@@ -113,6 +166,8 @@ type IntegerExpression struct {
 	// the integer token
 	Token token.Token
 	Value int64
+	// the value of a literal that overflows int64, nil otherwise
+	Big *big.Int
 }
 
 func (ie *IntegerExpression) expressionNode() {}
@@ -121,6 +176,14 @@ func (ie *IntegerExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IntegerExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
+func (ie *IntegerExpression) End() token.Position {
+	return ie.Token.End
+}
+
 func (ie *IntegerExpression) String() string {
 	return ie.Token.Literal
 }
@@ -133,6 +196,39 @@ func NewIntegerExpression(literal string, value int64) *IntegerExpression {
 	}
 }
 
+// FloatExpression implements the Expression interface
+type FloatExpression struct {
+	// the float token
+	Token token.Token
+	Value float64
+}
+
+func (fe *FloatExpression) expressionNode() {}
+
+func (fe *FloatExpression) TokenLiteral() string {
+	return fe.Token.Literal
+}
+
+func (fe *FloatExpression) Pos() token.Position {
+	return fe.Token.Pos
+}
+
+func (fe *FloatExpression) End() token.Position {
+	return fe.Token.End
+}
+
+func (fe *FloatExpression) String() string {
+	return fe.Token.Literal
+}
+
+// NewFloatExpression creates a Float node
+func NewFloatExpression(literal string, value float64) *FloatExpression {
+	return &FloatExpression{
+		Token: token.New(token.FLOAT, literal),
+		Value: value,
+	}
+}
+
 // BooleanExpression implements the Expression interface
 type BooleanExpression struct {
 	// the boolean token
@@ -146,6 +242,14 @@ func (be *BooleanExpression) TokenLiteral() string {
 	return be.Token.Literal
 }
 
+func (be *BooleanExpression) Pos() token.Position {
+	return be.Token.Pos
+}
+
+func (be *BooleanExpression) End() token.Position {
+	return be.Token.End
+}
+
 func (be *BooleanExpression) String() string {
 	return be.Token.Literal
 }
@@ -165,6 +269,37 @@ func NewBooleanExpression(value bool) *BooleanExpression {
 	}
 }
 
+// NilExpression implements the Expression interface
+type NilExpression struct {
+	// the nil token
+	Token token.Token
+}
+
+func (ne *NilExpression) expressionNode() {}
+
+func (ne *NilExpression) TokenLiteral() string {
+	return ne.Token.Literal
+}
+
+func (ne *NilExpression) Pos() token.Position {
+	return ne.Token.Pos
+}
+
+func (ne *NilExpression) End() token.Position {
+	return ne.Token.End
+}
+
+func (ne *NilExpression) String() string {
+	return ne.Token.Literal
+}
+
+// NewNilExpression creates a Nil node
+func NewNilExpression() *NilExpression {
+	return &NilExpression{
+		Token: token.New(token.NIL, "nil"),
+	}
+}
+
 // StringExpression implements the Expression interface
 type StringExpression struct {
 	// the string token
@@ -178,6 +313,14 @@ func (se *StringExpression) TokenLiteral() string {
 	return se.Token.Literal
 }
 
+func (se *StringExpression) Pos() token.Position {
+	return se.Token.Pos
+}
+
+func (se *StringExpression) End() token.Position {
+	return se.Token.End
+}
+
 func (se *StringExpression) String() string {
 	return se.Token.Literal
 }
@@ -190,11 +333,63 @@ func NewStringExpression(literal string) *StringExpression {
 	}
 }
 
+// InterpolatedStringExpression implements the Expression interface
+type InterpolatedStringExpression struct {
+	// the string head token, i.e. the text up to the first ${
+	Token token.Token
+	// the text around the interpolated expressions, there is always one more string than expressions
+	Strings []string
+	// the interpolated expressions
+	Expressions []Expression
+	// the position of the closing "
+	Rquote token.Position
+}
+
+func (ise *InterpolatedStringExpression) expressionNode() {}
+
+func (ise *InterpolatedStringExpression) TokenLiteral() string {
+	return ise.Token.Literal
+}
+
+func (ise *InterpolatedStringExpression) Pos() token.Position {
+	return ise.Token.Pos
+}
+
+func (ise *InterpolatedStringExpression) End() token.Position {
+	return after(ise.Rquote)
+}
+
+func (ise *InterpolatedStringExpression) String() string {
+	builder := strings.Builder{}
+
+	for i, exp := range ise.Expressions {
+		builder.WriteString(ise.Strings[i])
+		builder.WriteString("${")
+		builder.WriteString(exp.String())
+		builder.WriteString("}")
+	}
+
+	builder.WriteString(ise.Strings[len(ise.Strings)-1])
+
+	return builder.String()
+}
+
+// NewInterpolatedStringExpression creates an InterpolatedString node
+func NewInterpolatedStringExpression(strs []string, exps []Expression) *InterpolatedStringExpression {
+	return &InterpolatedStringExpression{
+		Token:       token.New(token.STRING_HEAD, strs[0]),
+		Strings:     strs,
+		Expressions: exps,
+	}
+}
+
 // ArrayExpression implements the Expression interface
 type ArrayExpression struct {
 	// the [ token
 	Token    token.Token
 	Elements []Expression
+	// the position of the closing ]
+	Rbracket token.Position
 }
 
 func (ae *ArrayExpression) expressionNode() {}
@@ -203,6 +398,14 @@ func (ae *ArrayExpression) TokenLiteral() string {
 	return ae.Token.Literal
 }
 
+func (ae *ArrayExpression) Pos() token.Position {
+	return ae.Token.Pos
+}
+
+func (ae *ArrayExpression) End() token.Position {
+	return after(ae.Rbracket)
+}
+
 func (ae *ArrayExpression) String() string {
 	builder := strings.Builder{}
 
@@ -231,6 +434,8 @@ type HashExpression struct {
 	// the { token
 	Token token.Token
 	Items map[Expression]Expression
+	// the position of the closing }
+	Rbrace token.Position
 }
 
 func (he *HashExpression) expressionNode() {}
@@ -239,6 +444,14 @@ func (he *HashExpression) TokenLiteral() string {
 	return he.Token.Literal
 }
 
+func (he *HashExpression) Pos() token.Position {
+	return he.Token.Pos
+}
+
+func (he *HashExpression) End() token.Position {
+	return after(he.Rbrace)
+}
+
 func (he *HashExpression) String() string {
 	builder := strings.Builder{}
 
@@ -268,6 +481,8 @@ type IndexExpression struct {
 	Token token.Token
 	Left  Expression
 	Index Expression
+	// the position of the closing ]
+	Rbracket token.Position
 }
 
 func (ie *IndexExpression) expressionNode() {}
@@ -276,6 +491,14 @@ func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IndexExpression) Pos() token.Position {
+	return ie.Left.Pos()
+}
+
+func (ie *IndexExpression) End() token.Position {
+	return after(ie.Rbracket)
+}
+
 func (ie *IndexExpression) String() string {
 	builder := strings.Builder{}
 
@@ -297,6 +520,60 @@ func NewIndexExpression(left, index Expression) *IndexExpression {
 	}
 }
 
+// SliceExpression implements the Expression interface
+type SliceExpression struct {
+	// the [ token
+	Token token.Token
+	Left  Expression
+	// Low and High are nil when they are omitted
+	Low  Expression
+	High Expression
+	// the position of the closing ]
+	Rbracket token.Position
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SliceExpression) Pos() token.Position {
+	return se.Left.Pos()
+}
+
+func (se *SliceExpression) End() token.Position {
+	return after(se.Rbracket)
+}
+
+func (se *SliceExpression) String() string {
+	builder := strings.Builder{}
+
+	builder.WriteString("(")
+	builder.WriteString(se.Left.String())
+	builder.WriteString("[")
+	if se.Low != nil {
+		builder.WriteString(se.Low.String())
+	}
+	builder.WriteString(":")
+	if se.High != nil {
+		builder.WriteString(se.High.String())
+	}
+	builder.WriteString("])")
+
+	return builder.String()
+}
+
+// NewSliceExpression creates a SliceExpression node
+func NewSliceExpression(left, low, high Expression) *SliceExpression {
+	return &SliceExpression{
+		Token: token.New(token.LBRACKET, "["),
+		Left:  left,
+		Low:   low,
+		High:  high,
+	}
+}
+
 // IfExpression implements the Expression interface
 type IfExpression struct {
 	// the if token
@@ -314,6 +591,18 @@ func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IfExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+
+	return ie.Consequence.End()
+}
+
 func (ie *IfExpression) String() string {
 	builder := strings.Builder{}
 
@@ -355,6 +644,14 @@ func (fe *FuncExpression) TokenLiteral() string {
 	return fe.Token.Literal
 }
 
+func (fe *FuncExpression) Pos() token.Position {
+	return fe.Token.Pos
+}
+
+func (fe *FuncExpression) End() token.Position {
+	return fe.Body.End()
+}
+
 func (fe *FuncExpression) String() string {
 	builder := strings.Builder{}
 
@@ -390,6 +687,8 @@ type CallExpression struct {
 	Func Expression
 	// function call arguments
 	Arguments []Expression
+	// the position of the closing )
+	Rparen token.Position
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -398,6 +697,14 @@ func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
 
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Func.Pos()
+}
+
+func (ce *CallExpression) End() token.Position {
+	return after(ce.Rparen)
+}
+
 func (ce *CallExpression) String() string {
 	builder := strings.Builder{}
 
@@ -448,6 +755,14 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position {
+	return pe.Token.Pos
+}
+
+func (pe *PrefixExpression) End() token.Position {
+	return pe.Operand.End()
+}
+
 func (pe *PrefixExpression) String() string {
 	builder := strings.Builder{}
 
@@ -486,6 +801,14 @@ func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *InfixExpression) Pos() token.Position {
+	return ie.LeftOperand.Pos()
+}
+
+func (ie *InfixExpression) End() token.Position {
+	return ie.RightOperand.End()
+}
+
 func (ie *InfixExpression) String() string {
 	builder := strings.Builder{}
 
@@ -508,6 +831,55 @@ func NewInfixExpression(literal string, leftOperand, rightOperand Expression) *I
 	}
 }
 
+// AssignExpression implements the Expression interface
+// it updates an existing binding, an array element or a hash entry
+type AssignExpression struct {
+	// a token representation of the assignment operator
+	Token token.Token
+	// the string literal of the assignment operator, e.g. = or +=
+	Operator string
+	// the identifier or index expression being assigned to
+	Target Expression
+	// the expression to the right of the assignment operator
+	Value Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+
+func (ae *AssignExpression) Pos() token.Position {
+	return ae.Target.Pos()
+}
+
+func (ae *AssignExpression) End() token.Position {
+	return ae.Value.End()
+}
+
+func (ae *AssignExpression) String() string {
+	builder := strings.Builder{}
+
+	builder.WriteString("(")
+	builder.WriteString(ae.Target.String() + " ")
+	builder.WriteString(ae.Operator + " ")
+	builder.WriteString(ae.Value.String())
+	builder.WriteString(")")
+
+	return builder.String()
+}
+
+// NewAssignExpression creates an AssignExpression node
+func NewAssignExpression(literal string, target, value Expression) *AssignExpression {
+	return &AssignExpression{
+		Token:    token.New(token.LookupTokenType(literal), literal),
+		Operator: literal,
+		Target:   target,
+		Value:    value,
+	}
+}
+
 // -------------- Statements -------------------
 
 // LetStatement represents the let statement
@@ -526,6 +898,18 @@ func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
+func (ls *LetStatement) Pos() token.Position {
+	return ls.Token.Pos
+}
+
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+
+	return ls.Identifier.End()
+}
+
 func (ls *LetStatement) String() string {
 	builder := strings.Builder{}
 
@@ -565,6 +949,18 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Pos
+}
+
+func (rs *ReturnStatement) End() token.Position {
+	if rs.Value != nil {
+		return rs.Value.End()
+	}
+
+	return rs.Token.End
+}
+
 func (rs *ReturnStatement) String() string {
 	builder := strings.Builder{}
 
@@ -595,6 +991,23 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode() {}
 
+// Pos is defined explicitly because an empty statement has no expression
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression == nil {
+		return token.Position{}
+	}
+
+	return es.Expression.Pos()
+}
+
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression == nil {
+		return token.Position{}
+	}
+
+	return es.Expression.End()
+}
+
 // NewExpressionStatement creates an ExpressionStatement node
 func NewExpressionStatement(exp Expression) *ExpressionStatement {
 	return &ExpressionStatement{
@@ -608,6 +1021,8 @@ type BlockStatement struct {
 	Token token.Token
 	// a series of statements grouped by {}
 	Statements []Statement
+	// the position of the closing }
+	Rbrace token.Position
 }
 
 func (bs *BlockStatement) statementNode() {}
@@ -616,6 +1031,14 @@ func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
 
+func (bs *BlockStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+
+func (bs *BlockStatement) End() token.Position {
+	return after(bs.Rbrace)
+}
+
 func (bs *BlockStatement) String() string {
 	builder := strings.Builder{}
 
@@ -640,3 +1063,253 @@ func NewBlockStatement(statements ...Statement) *BlockStatement {
 		Statements: statements,
 	}
 }
+
+// WhileStatement represents a loop running its body as long as the condition is truthy
+type WhileStatement struct {
+	// the while token
+	Token token.Token
+	// the condition expression
+	Condition Expression
+	// the loop body
+	Body *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+func (ws *WhileStatement) Pos() token.Position {
+	return ws.Token.Pos
+}
+
+func (ws *WhileStatement) End() token.Position {
+	return ws.Body.End()
+}
+
+func (ws *WhileStatement) String() string {
+	builder := strings.Builder{}
+
+	builder.WriteString("while" + " ")
+	builder.WriteString(ws.Condition.String() + " ")
+	builder.WriteString(ws.Body.String())
+
+	return builder.String()
+}
+
+// NewWhileStatement creates a WhileStatement node
+func NewWhileStatement(condition Expression, body *BlockStatement) *WhileStatement {
+	return &WhileStatement{
+		Token:     token.New(token.WHILE, "while"),
+		Condition: condition,
+		Body:      body,
+	}
+}
+
+// ForStatement represents a loop running its body once per element of an iterable
+type ForStatement struct {
+	// the for token
+	Token token.Token
+	// the identifier bound to the current element
+	Identifier *IdentifierExpression
+	// the expression producing the iterable
+	Iterable Expression
+	// the loop body
+	Body *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *ForStatement) Pos() token.Position {
+	return fs.Token.Pos
+}
+
+func (fs *ForStatement) End() token.Position {
+	return fs.Body.End()
+}
+
+func (fs *ForStatement) String() string {
+	builder := strings.Builder{}
+
+	builder.WriteString("for" + " ")
+	builder.WriteString(fs.Identifier.String() + " ")
+	builder.WriteString("in" + " ")
+	builder.WriteString(fs.Iterable.String() + " ")
+	builder.WriteString(fs.Body.String())
+
+	return builder.String()
+}
+
+// NewForStatement creates a ForStatement node
+func NewForStatement(identifier *IdentifierExpression, iterable Expression, body *BlockStatement) *ForStatement {
+	return &ForStatement{
+		Token:      token.New(token.FOR, "for"),
+		Identifier: identifier,
+		Iterable:   iterable,
+		Body:       body,
+	}
+}
+
+// BreakStatement terminates the innermost loop
+type BreakStatement struct {
+	// the break token
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+func (bs *BreakStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+
+func (bs *BreakStatement) End() token.Position {
+	return bs.Token.End
+}
+
+func (bs *BreakStatement) String() string {
+	return bs.TokenLiteral() + ";"
+}
+
+// NewBreakStatement creates a BreakStatement node
+func NewBreakStatement() *BreakStatement {
+	return &BreakStatement{
+		Token: token.New(token.BREAK, "break"),
+	}
+}
+
+// ContinueStatement skips the rest of the body of the innermost loop
+type ContinueStatement struct {
+	// the continue token
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ContinueStatement) Pos() token.Position {
+	return cs.Token.Pos
+}
+
+func (cs *ContinueStatement) End() token.Position {
+	return cs.Token.End
+}
+
+func (cs *ContinueStatement) String() string {
+	return cs.TokenLiteral() + ";"
+}
+
+// NewContinueStatement creates a ContinueStatement node
+func NewContinueStatement() *ContinueStatement {
+	return &ContinueStatement{
+		Token: token.New(token.CONTINUE, "continue"),
+	}
+}
+
+// TryStatement runs a block and handles the errors raised by it
+type TryStatement struct {
+	// the try token
+	Token token.Token
+	// the block being guarded
+	Block *BlockStatement
+	// the identifier bound to the caught error, nil when there is no catch clause
+	Parameter *IdentifierExpression
+	// the catch clause, nil when absent
+	Catch *BlockStatement
+	// the finally clause, nil when absent
+	Finally *BlockStatement
+}
+
+func (ts *TryStatement) statementNode() {}
+
+func (ts *TryStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *TryStatement) Pos() token.Position {
+	return ts.Token.Pos
+}
+
+func (ts *TryStatement) End() token.Position {
+	if ts.Finally != nil {
+		return ts.Finally.End()
+	}
+
+	return ts.Catch.End()
+}
+
+func (ts *TryStatement) String() string {
+	builder := strings.Builder{}
+
+	builder.WriteString("try" + " ")
+	builder.WriteString(ts.Block.String())
+
+	if ts.Catch != nil {
+		builder.WriteString(" catch ")
+		builder.WriteString(ts.Parameter.String() + " ")
+		builder.WriteString(ts.Catch.String())
+	}
+
+	if ts.Finally != nil {
+		builder.WriteString(" finally ")
+		builder.WriteString(ts.Finally.String())
+	}
+
+	return builder.String()
+}
+
+// NewTryStatement creates a TryStatement node, the catch or the finally clause can be nil but not both
+func NewTryStatement(block *BlockStatement, parameter *IdentifierExpression, catch *BlockStatement, finally *BlockStatement) *TryStatement {
+	return &TryStatement{
+		Token:     token.New(token.TRY, "try"),
+		Block:     block,
+		Parameter: parameter,
+		Catch:     catch,
+		Finally:   finally,
+	}
+}
+
+// ThrowStatement raises an error carrying any value
+type ThrowStatement struct {
+	// the throw token
+	Token token.Token
+	// the expression producing the thrown value
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode() {}
+
+func (ts *ThrowStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *ThrowStatement) Pos() token.Position {
+	return ts.Token.Pos
+}
+
+func (ts *ThrowStatement) End() token.Position {
+	return ts.Value.End()
+}
+
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// NewThrowStatement creates a ThrowStatement node
+func NewThrowStatement(value Expression) *ThrowStatement {
+	return &ThrowStatement{
+		Token: token.New(token.THROW, "throw"),
+		Value: value,
+	}
+}
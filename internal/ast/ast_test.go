@@ -75,6 +75,35 @@ var _ = Describe("Ast", func() {
 			})
 		})
 
+		Context("loop statements as a string", func() {
+			It("string output matches the while statement", func() {
+				whileStatement := ast.NewWhileStatement(identifierFoo, ast.NewBlockStatement(ast.NewBreakStatement()))
+				Expect(whileStatement.String()).To(Equal("while foo break;"))
+			})
+
+			It("string output matches the for statement", func() {
+				forStatement := ast.NewForStatement(identifierFoo, identifierBar, ast.NewBlockStatement(ast.NewContinueStatement()))
+				Expect(forStatement.String()).To(Equal("for foo in bar continue;"))
+			})
+		})
+
+		Context("error handling statements as a string", func() {
+			It("string output matches the try statement", func() {
+				tryStatement := ast.NewTryStatement(
+					ast.NewBlockStatement(ast.NewExpressionStatement(identifierFoo)),
+					identifierBar,
+					ast.NewBlockStatement(ast.NewExpressionStatement(identifierBar)),
+					ast.NewBlockStatement(ast.NewExpressionStatement(identifierFoo)),
+				)
+				Expect(tryStatement.String()).To(Equal("try foo catch bar bar finally foo"))
+			})
+
+			It("string output matches the throw statement", func() {
+				throwStatement := ast.NewThrowStatement(identifierFoo)
+				Expect(throwStatement.String()).To(Equal("throw foo;"))
+			})
+		})
+
 		Context("ExpressionStatement as a string", func() {
 			It("string output matches the expression statement", func() {
 			})
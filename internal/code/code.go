@@ -0,0 +1,284 @@
+package code
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Instructions is a flat sequence of encoded bytecode instructions
+type Instructions []byte
+
+func (ins Instructions) String() string {
+	builder := strings.Builder{}
+
+	for i := 0; i < len(ins); {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&builder, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&builder, "%04d %s\n", i, formatInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return builder.String()
+}
+
+func formatInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d", len(operands), len(def.OperandWidths))
+	}
+
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operand count for %s", def.Name)
+}
+
+// Opcode is the first byte of every instruction
+type Opcode byte
+
+const (
+	// OpConstant pushes the constant at the given index in the constant pool
+	OpConstant Opcode = iota
+	// OpPop pops the top of the stack
+	OpPop
+
+	// arithmetic operators
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpPow
+
+	// bitwise operators
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+
+	// literals without a constant pool entry
+	OpTrue
+	OpFalse
+	OpNil
+
+	// comparison operators
+	OpEqual
+	OpNotEqual
+	OpLessThan
+	OpLessThanEqual
+	OpGreaterThan
+	OpGreaterThanEqual
+
+	// prefix operators
+	OpMinus
+	OpBang
+	OpBitNot
+
+	// control flow, operands are absolute instruction offsets
+	OpJumpNotTruthy
+	OpJump
+	// logical operators, the operand is kept when it decides the result and popped otherwise
+	OpJumpNotTruthyOrPop
+	OpJumpTruthyOrPop
+	// loops over an iterable, OpIter replaces the iterable with its elements and the position 0,
+	// OpIterNext takes them back and pushes the next element and position or jumps once they run out
+	OpIter
+	OpIterNext
+
+	// bindings
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpGetBuiltin
+	OpGetFree
+	OpCurrentClosure
+
+	// composite literals and indexing
+	OpArray
+	OpHash
+	OpIndex
+	// OpSetIndex assigns to an array element or a hash entry, OpUpdateIndex applies the operator of
+	// the opcode in its operand to the current value first
+	OpSetIndex
+	OpUpdateIndex
+	// the operand is a combination of SliceLow and SliceHigh telling which indexes are on the stack
+	OpSlice
+	// the operand is the number of parts of an interpolated string, they are joined in their printed form
+	OpInterpolate
+
+	// functions
+	OpClosure
+	OpCall
+	OpReturnValue
+)
+
+// flags of OpSlice, an omitted index is not pushed
+const (
+	SliceLow = 1 << iota
+	SliceHigh
+)
+
+// Definition describes the name of an opcode and the width in bytes of each of its operands
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:           {"OpConstant", []int{2}},
+	OpPop:                {"OpPop", []int{}},
+	OpAdd:                {"OpAdd", []int{}},
+	OpSub:                {"OpSub", []int{}},
+	OpMul:                {"OpMul", []int{}},
+	OpDiv:                {"OpDiv", []int{}},
+	OpMod:                {"OpMod", []int{}},
+	OpPow:                {"OpPow", []int{}},
+	OpBitAnd:             {"OpBitAnd", []int{}},
+	OpBitOr:              {"OpBitOr", []int{}},
+	OpBitXor:             {"OpBitXor", []int{}},
+	OpShiftLeft:          {"OpShiftLeft", []int{}},
+	OpShiftRight:         {"OpShiftRight", []int{}},
+	OpTrue:               {"OpTrue", []int{}},
+	OpFalse:              {"OpFalse", []int{}},
+	OpNil:                {"OpNil", []int{}},
+	OpEqual:              {"OpEqual", []int{}},
+	OpNotEqual:           {"OpNotEqual", []int{}},
+	OpLessThan:           {"OpLessThan", []int{}},
+	OpLessThanEqual:      {"OpLessThanEqual", []int{}},
+	OpGreaterThan:        {"OpGreaterThan", []int{}},
+	OpGreaterThanEqual:   {"OpGreaterThanEqual", []int{}},
+	OpMinus:              {"OpMinus", []int{}},
+	OpBang:               {"OpBang", []int{}},
+	OpBitNot:             {"OpBitNot", []int{}},
+	OpJumpNotTruthy:      {"OpJumpNotTruthy", []int{2}},
+	OpJump:               {"OpJump", []int{2}},
+	OpJumpNotTruthyOrPop: {"OpJumpNotTruthyOrPop", []int{2}},
+	OpJumpTruthyOrPop:    {"OpJumpTruthyOrPop", []int{2}},
+	OpIter:               {"OpIter", []int{}},
+	OpIterNext:           {"OpIterNext", []int{2}},
+	OpGetGlobal:          {"OpGetGlobal", []int{2}},
+	OpSetGlobal:          {"OpSetGlobal", []int{2}},
+	OpGetLocal:           {"OpGetLocal", []int{1}},
+	OpSetLocal:           {"OpSetLocal", []int{1}},
+	// the operand is the index of the builtin name in the constant pool
+	OpGetBuiltin:     {"OpGetBuiltin", []int{2}},
+	OpGetFree:        {"OpGetFree", []int{1}},
+	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+	OpArray:          {"OpArray", []int{2}},
+	OpHash:           {"OpHash", []int{2}},
+	OpIndex:          {"OpIndex", []int{}},
+	OpSetIndex:       {"OpSetIndex", []int{}},
+	OpUpdateIndex:    {"OpUpdateIndex", []int{1}},
+	OpSlice:          {"OpSlice", []int{1}},
+	OpInterpolate:    {"OpInterpolate", []int{2}},
+	// the operands are the constant index of the function and the number of free variables
+	OpClosure:     {"OpClosure", []int{2, 1}},
+	OpCall:        {"OpCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+}
+
+// Lookup finds the definition of an opcode
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUndefinedOpcode, op)
+	}
+
+	return def, nil
+}
+
+// CheckOperands reports an error when an operand does not fit in the width of its definition,
+// Make would truncate it
+func CheckOperands(op Opcode, operands ...int) error {
+	def, err := Lookup(byte(op))
+	if err != nil {
+		return err
+	}
+
+	for i, width := range def.OperandWidths {
+		if i >= len(operands) {
+			break
+		}
+
+		if maxOperand := 1<<(8*width) - 1; operands[i] < 0 || operands[i] > maxOperand {
+			return fmt.Errorf("%w: %s operand %d is %d, at most %d is allowed", ErrOperandTooLarge, def.Name, i, operands[i], maxOperand)
+		}
+	}
+
+	return nil
+}
+
+// Make encodes a single instruction, operands are stored in big endian
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction and returns them with the number of bytes read
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+
+		offset += width
+	}
+
+	return operands, offset
+}
+
+// ReadUint16 decodes a 2-byte operand
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// ReadUint8 decodes a 1-byte operand
+func ReadUint8(ins Instructions) uint8 {
+	return uint8(ins[0])
+}
@@ -0,0 +1,13 @@
+package code_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCode(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Code Suite")
+}
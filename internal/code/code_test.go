@@ -0,0 +1,53 @@
+package code_test
+
+import (
+	"github.com/aden-q/monkey/internal/code"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Code", func() {
+	Describe("Make", func() {
+		It("instruction with a 2-byte operand", func() {
+			Expect(code.Make(code.OpConstant, 65534)).To(Equal([]byte{byte(code.OpConstant), 255, 254}))
+		})
+
+		It("instruction without operands", func() {
+			Expect(code.Make(code.OpAdd)).To(Equal([]byte{byte(code.OpAdd)}))
+		})
+
+		It("instruction with mixed operand widths", func() {
+			Expect(code.Make(code.OpClosure, 65534, 255)).To(Equal([]byte{byte(code.OpClosure), 255, 254, 255}))
+		})
+	})
+
+	Describe("ReadOperands", func() {
+		It("reads back the encoded operands", func() {
+			def, err := code.Lookup(byte(code.OpClosure))
+			Expect(err).ToNot(HaveOccurred())
+
+			instruction := code.Make(code.OpClosure, 65535, 255)
+			operands, read := code.ReadOperands(def, instruction[1:])
+			Expect(read).To(Equal(3))
+			Expect(operands).To(Equal([]int{65535, 255}))
+		})
+
+		It("undefined opcode", func() {
+			_, err := code.Lookup(255)
+			Expect(err).To(MatchError(code.ErrUndefinedOpcode))
+		})
+	})
+
+	Describe("String", func() {
+		It("disassembles instructions", func() {
+			instructions := code.Instructions{}
+			instructions = append(instructions, code.Make(code.OpAdd)...)
+			instructions = append(instructions, code.Make(code.OpGetLocal, 1)...)
+			instructions = append(instructions, code.Make(code.OpConstant, 2)...)
+			instructions = append(instructions, code.Make(code.OpClosure, 65535, 255)...)
+
+			expected := "0000 OpAdd\n0001 OpGetLocal 1\n0003 OpConstant 2\n0006 OpClosure 65535 255\n"
+			Expect(instructions.String()).To(Equal(expected))
+		})
+	})
+})
@@ -0,0 +1,10 @@
+package code
+
+import (
+	"errors"
+)
+
+var (
+	ErrUndefinedOpcode = errors.New("undefined opcode")
+	ErrOperandTooLarge = errors.New("operand too large")
+)
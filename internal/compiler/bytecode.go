@@ -0,0 +1,293 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/object"
+)
+
+// interface compliance check
+var _ encoding.BinaryMarshaler = (*Bytecode)(nil)
+var _ encoding.BinaryUnmarshaler = (*Bytecode)(nil)
+
+// Magic is the file signature at the start of every encoded bytecode file
+const Magic = "\x00MKC"
+
+// Version is bumped every time the instruction set or the encoding changes
+const Version byte = 9
+
+// tags identifying the type of an encoded constant
+const (
+	integerConstant byte = iota + 1
+	stringConstant
+	compiledFuncConstant
+	bigIntegerConstant
+	floatConstant
+)
+
+// Bytecode is the output of the compiler and the input of the VM
+type Bytecode struct {
+	Instructions code.Instructions
+	Constants    []object.Object
+}
+
+// IsBytecode reports whether the data starts with the bytecode file signature
+func IsBytecode(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Magic))
+}
+
+// MarshalBinary encodes the bytecode so that it can be written to disk. The layout is the magic,
+// the version, the top level instructions, then the constant pool. Every constant is a tag byte
+// followed by its payload, lengths and numbers are varint encoded
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	buf := []byte(Magic)
+	buf = append(buf, Version)
+	buf = appendBytes(buf, b.Instructions)
+	buf = binary.AppendUvarint(buf, uint64(len(b.Constants)))
+
+	for _, constant := range b.Constants {
+		switch constant := constant.(type) {
+		case *object.Integer:
+			if constant.IsBig() {
+				buf = append(buf, bigIntegerConstant)
+				buf = appendBytes(buf, []byte(constant.Big.String()))
+				continue
+			}
+
+			buf = append(buf, integerConstant)
+			buf = binary.AppendVarint(buf, constant.Value)
+		case *object.Float:
+			buf = append(buf, floatConstant)
+			buf = binary.AppendUvarint(buf, math.Float64bits(constant.Value))
+		case *object.String:
+			buf = append(buf, stringConstant)
+			buf = appendBytes(buf, []byte(constant.Value))
+		case *object.CompiledFunc:
+			buf = append(buf, compiledFuncConstant)
+			buf = binary.AppendUvarint(buf, uint64(constant.NumLocals))
+			buf = binary.AppendUvarint(buf, uint64(constant.NumParameters))
+			buf = appendBytes(buf, constant.Instructions)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedConstant, constant.Type())
+		}
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary decodes bytecode previously encoded by MarshalBinary
+func (b *Bytecode) UnmarshalBinary(data []byte) error {
+	if !IsBytecode(data) {
+		return fmt.Errorf("%w: missing file signature", ErrInvalidBytecode)
+	}
+
+	r := bytes.NewReader(data[len(Magic):])
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return invalidBytecode(err)
+	}
+
+	if version != Version {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBytecode, version)
+	}
+
+	instructions, err := readBytes(r)
+	if err != nil {
+		return invalidBytecode(err)
+	}
+
+	numConstants, err := binary.ReadUvarint(r)
+	if err != nil {
+		return invalidBytecode(err)
+	}
+
+	constants := make([]object.Object, 0, numConstants)
+	for i := uint64(0); i < numConstants; i++ {
+		constant, err := readConstant(r)
+		if err != nil {
+			return invalidBytecode(err)
+		}
+
+		constants = append(constants, constant)
+	}
+
+	if err := validate(instructions, constants, 0); err != nil {
+		return invalidBytecode(err)
+	}
+
+	for _, constant := range constants {
+		if fn, ok := constant.(*object.CompiledFunc); ok {
+			if err := validate(fn.Instructions, constants, fn.NumLocals); err != nil {
+				return invalidBytecode(err)
+			}
+		}
+	}
+
+	b.Instructions = instructions
+	b.Constants = constants
+
+	return nil
+}
+
+func readConstant(r *bytes.Reader) (object.Object, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case integerConstant:
+		value, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return object.NewInteger(value), nil
+	case bigIntegerConstant:
+		// big integers are stored in decimal
+		text, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := new(big.Int).SetString(string(text), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+
+		return object.NewBigInteger(value), nil
+	case floatConstant:
+		// floats are stored as their IEEE 754 bits
+		bits, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return object.NewFloat(math.Float64frombits(bits)), nil
+	case stringConstant:
+		value, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return object.NewString(string(value)), nil
+	case compiledFuncConstant:
+		numLocals, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		numParameters, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		instructions, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+
+		// local operands are one byte wide and the parameters are the first locals
+		if numLocals > math.MaxUint8+1 || numParameters > numLocals {
+			return nil, fmt.Errorf("invalid function with %d locals and %d parameters", numLocals, numParameters)
+		}
+
+		return object.NewCompiledFunc(instructions, int(numLocals), int(numParameters)), nil
+	default:
+		return nil, fmt.Errorf("%w: tag %d", ErrUnsupportedConstant, tag)
+	}
+}
+
+// validate checks that instructions only use defined opcodes with all their operands, and that the
+// operands refer to existing constants, locals and instruction boundaries, so that the VM can trust them
+func validate(ins code.Instructions, constants []object.Object, numLocals int) error {
+	starts := map[int]bool{}
+	jumps := []int{}
+
+	for ip := 0; ip < len(ins); {
+		def, err := code.Lookup(ins[ip])
+		if err != nil {
+			return err
+		}
+
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+
+		if ip+1+width > len(ins) {
+			return fmt.Errorf("%s at %d is missing operands", def.Name, ip)
+		}
+
+		operands, read := code.ReadOperands(def, ins[ip+1:])
+
+		switch op := code.Opcode(ins[ip]); op {
+		case code.OpConstant, code.OpGetBuiltin, code.OpClosure:
+			if operands[0] >= len(constants) {
+				return fmt.Errorf("%s at %d refers to constant %d out of %d", def.Name, ip, operands[0], len(constants))
+			}
+
+			constant := constants[operands[0]]
+			if _, ok := constant.(*object.String); op == code.OpGetBuiltin && !ok {
+				return fmt.Errorf("%s at %d refers to a %s constant", def.Name, ip, constant.Type())
+			}
+
+			if _, ok := constant.(*object.CompiledFunc); op == code.OpClosure && !ok {
+				return fmt.Errorf("%s at %d refers to a %s constant", def.Name, ip, constant.Type())
+			}
+		case code.OpGetLocal, code.OpSetLocal:
+			if operands[0] >= numLocals {
+				return fmt.Errorf("%s at %d refers to local %d out of %d", def.Name, ip, operands[0], numLocals)
+			}
+		case code.OpJump, code.OpJumpNotTruthy, code.OpJumpNotTruthyOrPop, code.OpJumpTruthyOrPop, code.OpIterNext:
+			jumps = append(jumps, operands[0])
+		}
+
+		starts[ip] = true
+		ip += 1 + read
+	}
+
+	// jumping to the end of the instructions is allowed, it stops execution
+	for _, target := range jumps {
+		if target != len(ins) && !starts[target] {
+			return fmt.Errorf("jump to %d is not the start of an instruction", target)
+		}
+	}
+
+	return nil
+}
+
+// appendBytes appends a length-prefixed byte slice
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// readBytes reads a length-prefixed byte slice
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+
+	return b, err
+}
+
+func invalidBytecode(err error) error {
+	return fmt.Errorf("%w: %v", ErrInvalidBytecode, err)
+}
@@ -0,0 +1,796 @@
+package compiler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aden-q/monkey/internal/ast"
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/object"
+)
+
+// interface compliance check
+var _ Compiler = (*compiler)(nil)
+
+// A compiler interface that lowers an AST into bytecode
+type Compiler interface {
+	Compile(node ast.Node) error
+	Bytecode() *Bytecode
+}
+
+// emittedInstruction keeps track of an instruction already written to the current scope
+type emittedInstruction struct {
+	Opcode   code.Opcode
+	Position int
+}
+
+// compilationScope holds the instructions of a single function body being compiled
+type compilationScope struct {
+	instructions        code.Instructions
+	lastInstruction     emittedInstruction
+	previousInstruction emittedInstruction
+	// the loops enclosing the instructions being compiled, the innermost one last
+	loops []*loop
+}
+
+// loop keeps track of where break and continue statements jump to in a loop being compiled
+type loop struct {
+	// the position continue statements jump back to
+	start int
+	// the positions of the jumps of break statements, they are back-patched once the end is known
+	breakJumps []int
+}
+
+type compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	// a stack of scopes, one per nested function literal
+	scopes     []compilationScope
+	scopeIndex int
+
+	// the first operand that did not fit in its instruction, it is reported once the
+	// compilation of the current node ends
+	err error
+}
+
+func New() Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+// NewWithState creates a compiler that keeps using the given symbol table and constant pool,
+// this allows globals to survive between compilations (e.g. in a REPL)
+func NewWithState(symbolTable *SymbolTable, constants []object.Object) Compiler {
+	return &compiler{
+		constants:   constants,
+		symbolTable: symbolTable,
+		scopes:      []compilationScope{{instructions: code.Instructions{}}},
+		scopeIndex:  0,
+	}
+}
+
+// Compile lowers an AST node recursively
+func (c *compiler) Compile(node ast.Node) error {
+	if err := c.compile(node); err != nil {
+		return err
+	}
+
+	return c.err
+}
+
+func (c *compiler) compile(node ast.Node) error {
+	if node == nil {
+		return ErrEmptyNodeInput
+	}
+
+	// explicitly polymorphic switch statement
+	switch node := node.(type) {
+	// compile the program, the value of its last statement is popped as the result
+	case *ast.Program:
+		if err := c.compileBlock(node.Statements); err != nil {
+			return err
+		}
+
+		c.emit(code.OpPop)
+	// compile statements
+	case *ast.ExpressionStatement:
+		if node.Expression == nil {
+			return ErrEmptyNodeInput
+		}
+
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+
+		c.emit(code.OpPop)
+	case *ast.LetStatement:
+		return c.compileLetStatement(node)
+	case *ast.ReturnStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+
+		c.emit(code.OpReturnValue)
+	case *ast.TryStatement, *ast.ThrowStatement:
+		// exceptions are only implemented by the evaluator
+		return fmt.Errorf("%w: %s statements", ErrNotSupported, node.TokenLiteral())
+	case *ast.WhileStatement:
+		return c.compileWhileStatement(node)
+	case *ast.ForStatement:
+		return c.compileForStatement(node)
+	case *ast.BreakStatement:
+		return c.compileLoopControlStatement(true)
+	case *ast.ContinueStatement:
+		return c.compileLoopControlStatement(false)
+	// compile expressions
+	case *ast.IdentifierExpression:
+		c.loadSymbol(c.resolve(node.Value))
+	case *ast.IntegerExpression:
+		if node.Big != nil {
+			c.emit(code.OpConstant, c.addConstant(object.NewBigInteger(node.Big)))
+			return nil
+		}
+
+		c.emit(code.OpConstant, c.addConstant(object.NewInteger(node.Value)))
+	case *ast.FloatExpression:
+		c.emit(code.OpConstant, c.addConstant(object.NewFloat(node.Value)))
+	case *ast.BooleanExpression:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.NilExpression:
+		c.emit(code.OpNil)
+	case *ast.StringExpression:
+		c.emit(code.OpConstant, c.addConstant(object.NewString(node.Value)))
+	case *ast.InterpolatedStringExpression:
+		return c.compileInterpolatedStringExpression(node)
+	case *ast.ArrayExpression:
+		return c.compileArrayExpression(node)
+	case *ast.HashExpression:
+		return c.compileHashExpression(node)
+	case *ast.IndexExpression:
+		return c.compileIndexExpression(node)
+	case *ast.SliceExpression:
+		return c.compileSliceExpression(node)
+	case *ast.IfExpression:
+		return c.compileIfExpression(node)
+	case *ast.FuncExpression:
+		return c.compileFuncExpression(node, "")
+	case *ast.CallExpression:
+		return c.compileCallExpression(node)
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(node)
+	case *ast.InfixExpression:
+		return c.compileInfixExpression(node)
+	case *ast.AssignExpression:
+		return c.compileAssignExpression(node)
+	default:
+		// no match, unexpected path
+		return ErrUnexpectedNodeType
+	}
+
+	return nil
+}
+
+// Bytecode returns the instructions of the outermost scope together with the constant pool
+func (c *compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+	}
+}
+
+// compileBlock compiles a series of statements so that exactly one value is left on the stack,
+// the value of the last statement, or nil when the last statement does not produce a value
+func (c *compiler) compileBlock(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		if err := c.Compile(stmt); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case c.lastInstructionIs(code.OpPop):
+		c.removeLastPop()
+	case !c.lastInstructionIs(code.OpReturnValue) || len(stmts) == 0:
+		c.emit(code.OpNil)
+	}
+
+	return nil
+}
+
+func (c *compiler) compileLetStatement(stmt *ast.LetStatement) error {
+	var err error
+
+	// a function bound by let is able to refer to itself by name
+	if fe, ok := stmt.Value.(*ast.FuncExpression); ok {
+		err = c.compileFuncExpression(fe, stmt.Identifier.Value)
+	} else {
+		err = c.Compile(stmt.Value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.storeSymbol(c.symbolTable.Define(stmt.Identifier.Value))
+
+	return nil
+}
+
+// compileWhileStatement compiles a loop, like let statements loops leave nothing on the stack
+func (c *compiler) compileWhileStatement(ws *ast.WhileStatement) error {
+	l := c.enterLoop()
+
+	if err := c.Compile(ws.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 0xFFFF)
+	l.breakJumps = append(l.breakJumps, jumpNotTruthyPos)
+
+	if err := c.compileLoopBody(ws.Body); err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, l.start)
+	c.leaveLoop()
+
+	return nil
+}
+
+func (c *compiler) compileForStatement(fs *ast.ForStatement) error {
+	if err := c.Compile(fs.Iterable); err != nil {
+		return err
+	}
+
+	// the elements and the position of a loop are kept in bindings whose names are not valid
+	// identifiers, so that break and continue can jump anywhere without unbalancing the stack
+	depth := len(c.scopes[c.scopeIndex].loops)
+	elements := c.symbolTable.Define(fmt.Sprintf("for %d elements", depth))
+	position := c.symbolTable.Define(fmt.Sprintf("for %d position", depth))
+
+	c.emit(code.OpIter)
+	c.storeSymbol(position)
+	c.storeSymbol(elements)
+
+	l := c.enterLoop()
+
+	c.loadSymbol(elements)
+	c.loadSymbol(position)
+	iterNextPos := c.emit(code.OpIterNext, 0xFFFF)
+	l.breakJumps = append(l.breakJumps, iterNextPos)
+
+	// the loop variable is bound in the enclosing scope, the same way let does
+	c.storeSymbol(position)
+	c.storeSymbol(c.symbolTable.Define(fs.Identifier.Value))
+
+	if err := c.compileLoopBody(fs.Body); err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, l.start)
+	c.leaveLoop()
+
+	return nil
+}
+
+// compileLoopBody compiles the statements of a loop body, they leave nothing on the stack
+func (c *compiler) compileLoopBody(body *ast.BlockStatement) error {
+	for _, stmt := range body.Statements {
+		if err := c.Compile(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileLoopControlStatement jumps to the end of the innermost loop for a break statement and
+// back to its start for a continue statement
+func (c *compiler) compileLoopControlStatement(isBreak bool) error {
+	loops := c.scopes[c.scopeIndex].loops
+	if len(loops) == 0 {
+		return ErrUnexpectedNodeType
+	}
+
+	l := loops[len(loops)-1]
+	if !isBreak {
+		c.emit(code.OpJump, l.start)
+		return nil
+	}
+
+	l.breakJumps = append(l.breakJumps, c.emit(code.OpJump, 0xFFFF))
+
+	return nil
+}
+
+// enterLoop starts a loop at the current position
+func (c *compiler) enterLoop() *loop {
+	l := &loop{start: len(c.currentInstructions())}
+	c.scopes[c.scopeIndex].loops = append(c.scopes[c.scopeIndex].loops, l)
+
+	return l
+}
+
+// leaveLoop ends the innermost loop at the current position, its break statements jump there
+func (c *compiler) leaveLoop() {
+	loops := c.scopes[c.scopeIndex].loops
+	l := loops[len(loops)-1]
+	c.scopes[c.scopeIndex].loops = loops[:len(loops)-1]
+
+	for _, pos := range l.breakJumps {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+}
+
+// compileInterpolatedStringExpression pushes the text around the interpolated expressions and the
+// expressions themselves, empty text is left out
+func (c *compiler) compileInterpolatedStringExpression(ise *ast.InterpolatedStringExpression) error {
+	numParts := 0
+
+	for i, str := range ise.Strings {
+		if str != "" {
+			c.emit(code.OpConstant, c.addConstant(object.NewString(str)))
+			numParts++
+		}
+
+		if i == len(ise.Expressions) {
+			break
+		}
+
+		if err := c.Compile(ise.Expressions[i]); err != nil {
+			return err
+		}
+
+		numParts++
+	}
+
+	c.emit(code.OpInterpolate, numParts)
+
+	return nil
+}
+
+func (c *compiler) compileArrayExpression(ae *ast.ArrayExpression) error {
+	for _, el := range ae.Elements {
+		if err := c.Compile(el); err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpArray, len(ae.Elements))
+
+	return nil
+}
+
+func (c *compiler) compileHashExpression(he *ast.HashExpression) error {
+	keys := make([]ast.Expression, 0, len(he.Items))
+	for key := range he.Items {
+		keys = append(keys, key)
+	}
+
+	// map iteration order is random, sort the keys to make the output deterministic
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
+		if err := c.Compile(key); err != nil {
+			return err
+		}
+
+		if err := c.Compile(he.Items[key]); err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpHash, len(keys)*2)
+
+	return nil
+}
+
+func (c *compiler) compileIndexExpression(ie *ast.IndexExpression) error {
+	if err := c.Compile(ie.Left); err != nil {
+		return err
+	}
+
+	if err := c.Compile(ie.Index); err != nil {
+		return err
+	}
+
+	c.emit(code.OpIndex)
+
+	return nil
+}
+
+func (c *compiler) compileSliceExpression(se *ast.SliceExpression) error {
+	if err := c.Compile(se.Left); err != nil {
+		return err
+	}
+
+	bounds := 0
+
+	if se.Low != nil {
+		if err := c.Compile(se.Low); err != nil {
+			return err
+		}
+
+		bounds |= code.SliceLow
+	}
+
+	if se.High != nil {
+		if err := c.Compile(se.High); err != nil {
+			return err
+		}
+
+		bounds |= code.SliceHigh
+	}
+
+	c.emit(code.OpSlice, bounds)
+
+	return nil
+}
+
+func (c *compiler) compileIfExpression(ie *ast.IfExpression) error {
+	if err := c.Compile(ie.Condition); err != nil {
+		return err
+	}
+
+	// emit with a placeholder offset, it is back-patched once the consequence is compiled
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 0xFFFF)
+
+	if err := c.compileBlock(ie.Consequence.Statements); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(code.OpJump, 0xFFFF)
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	if ie.Alternative == nil {
+		c.emit(code.OpNil)
+	} else if err := c.compileBlock(ie.Alternative.Statements); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+func (c *compiler) compileFuncExpression(fe *ast.FuncExpression, name string) error {
+	c.enterScope()
+
+	if name != "" {
+		c.symbolTable.DefineFunctionName(name)
+	}
+
+	for _, param := range fe.Parameters {
+		c.symbolTable.Define(param.Value)
+	}
+
+	if err := c.compileBlock(fe.Body.Statements); err != nil {
+		return err
+	}
+
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturnValue)
+	}
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.NumDefinitions()
+	instructions := c.leaveScope()
+
+	// locals are addressed by a one byte operand
+	if numLocals > math.MaxUint8+1 {
+		return fmt.Errorf("%w: function with %d locals, at most %d are allowed", ErrTooLarge, numLocals, math.MaxUint8+1)
+	}
+
+	// push the captured values so that the closure can be created at runtime
+	for _, s := range freeSymbols {
+		c.loadSymbol(s)
+	}
+
+	fn := object.NewCompiledFunc(instructions, numLocals, len(fe.Parameters))
+	c.emit(code.OpClosure, c.addConstant(fn), len(freeSymbols))
+
+	return nil
+}
+
+func (c *compiler) compileCallExpression(ce *ast.CallExpression) error {
+	if err := c.Compile(ce.Func); err != nil {
+		return err
+	}
+
+	for _, arg := range ce.Arguments {
+		if err := c.Compile(arg); err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpCall, len(ce.Arguments))
+
+	return nil
+}
+
+func (c *compiler) compilePrefixExpression(pe *ast.PrefixExpression) error {
+	if err := c.Compile(pe.Operand); err != nil {
+		return err
+	}
+
+	switch pe.Operator {
+	case "!":
+		c.emit(code.OpBang)
+	case "-":
+		c.emit(code.OpMinus)
+	case "~":
+		c.emit(code.OpBitNot)
+	default:
+		return ErrUnexpectedOperatorType
+	}
+
+	return nil
+}
+
+// infixOpcodes maps infix operators to the opcode implementing them
+var infixOpcodes = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"%":  code.OpMod,
+	"**": code.OpPow,
+	"&":  code.OpBitAnd,
+	"|":  code.OpBitOr,
+	"^":  code.OpBitXor,
+	"<<": code.OpShiftLeft,
+	">>": code.OpShiftRight,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	"<":  code.OpLessThan,
+	"<=": code.OpLessThanEqual,
+	">":  code.OpGreaterThan,
+	">=": code.OpGreaterThanEqual,
+}
+
+// logicalOpcodes maps the logical operators to the jump skipping their right operand
+var logicalOpcodes = map[string]code.Opcode{
+	"&&": code.OpJumpNotTruthyOrPop,
+	"||": code.OpJumpTruthyOrPop,
+}
+
+func (c *compiler) compileInfixExpression(ie *ast.InfixExpression) error {
+	if jump, ok := logicalOpcodes[ie.Operator]; ok {
+		return c.compileLogicalExpression(ie, jump)
+	}
+
+	op, ok := infixOpcodes[ie.Operator]
+	if !ok {
+		return ErrUnexpectedOperatorType
+	}
+
+	if err := c.Compile(ie.LeftOperand); err != nil {
+		return err
+	}
+
+	if err := c.Compile(ie.RightOperand); err != nil {
+		return err
+	}
+
+	c.emit(op)
+
+	return nil
+}
+
+// compileLogicalExpression short-circuits the right operand, the expression evaluates to the
+// operand that decides the result
+func (c *compiler) compileLogicalExpression(ie *ast.InfixExpression, jump code.Opcode) error {
+	if err := c.Compile(ie.LeftOperand); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(jump, 0xFFFF)
+
+	if err := c.Compile(ie.RightOperand); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+// compileAssignExpression compiles an assignment to a binding declared before, an array element or a
+// hash entry, the assignment evaluates to the assigned value. Compound assignments such as += apply
+// the operator to the current value first
+func (c *compiler) compileAssignExpression(ae *ast.AssignExpression) error {
+	var op code.Opcode
+
+	compound := ae.Operator != "="
+	if compound {
+		var ok bool
+
+		// strip the trailing = of the compound operator to get the infix operator
+		if op, ok = infixOpcodes[strings.TrimSuffix(ae.Operator, "=")]; !ok {
+			return ErrUnexpectedOperatorType
+		}
+	}
+
+	switch target := ae.Target.(type) {
+	case *ast.IdentifierExpression:
+		symbol, ok := c.symbolTable.Resolve(target.Value)
+		if !ok || symbol.Scope == BuiltinScope {
+			return fmt.Errorf("%w: %s", ErrAssignToUndeclared, target.Value)
+		}
+
+		// closures capture the values of free variables, not the variables themselves
+		if symbol.Scope != GlobalScope && symbol.Scope != LocalScope {
+			return fmt.Errorf("%w: assignment to the captured variable %s", ErrNotSupported, target.Value)
+		}
+
+		if compound {
+			c.loadSymbol(symbol)
+		}
+
+		if err := c.Compile(ae.Value); err != nil {
+			return err
+		}
+
+		if compound {
+			c.emit(op)
+		}
+
+		c.storeSymbol(symbol)
+		c.loadSymbol(symbol)
+	case *ast.IndexExpression:
+		if err := c.Compile(target.Left); err != nil {
+			return err
+		}
+
+		if err := c.Compile(target.Index); err != nil {
+			return err
+		}
+
+		if err := c.Compile(ae.Value); err != nil {
+			return err
+		}
+
+		if compound {
+			c.emit(code.OpUpdateIndex, int(op))
+		} else {
+			c.emit(code.OpSetIndex)
+		}
+	default:
+		return ErrUnexpectedNodeType
+	}
+
+	return nil
+}
+
+// resolve finds the symbol an identifier refers to. Names that are not bound yet are
+// looked up in the builtins, otherwise they become globals which may be defined later on
+func (c *compiler) resolve(name string) Symbol {
+	if symbol, ok := c.symbolTable.Resolve(name); ok {
+		return symbol
+	}
+
+	global := c.symbolTable.global()
+
+	if _, ok := object.BuiltinFuncs[name]; ok {
+		global.DefineBuiltin(c.addConstant(object.NewString(name)), name)
+	} else {
+		global.Define(name)
+	}
+
+	symbol, _ := c.symbolTable.Resolve(name)
+
+	return symbol
+}
+
+// storeSymbol pops the top of the stack into a global or a local
+func (c *compiler) storeSymbol(s Symbol) {
+	if s.Scope == GlobalScope {
+		c.emit(code.OpSetGlobal, s.Index)
+	} else {
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
+
+func (c *compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpGetLocal, s.Index)
+	case BuiltinScope:
+		c.emit(code.OpGetBuiltin, s.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, s.Index)
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	}
+}
+
+// addConstant appends an object to the constant pool and returns its index
+func (c *compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+
+	return len(c.constants) - 1
+}
+
+// emit writes an instruction to the current scope and returns its position
+func (c *compiler) emit(op code.Opcode, operands ...int) int {
+	c.checkOperands(op, operands...)
+
+	ins := code.Make(op, operands...)
+	pos := len(c.currentInstructions())
+
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+
+	c.scopes[c.scopeIndex].previousInstruction = c.scopes[c.scopeIndex].lastInstruction
+	c.scopes[c.scopeIndex].lastInstruction = emittedInstruction{
+		Opcode:   op,
+		Position: pos,
+	}
+
+	return pos
+}
+
+func (c *compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *compiler) removeLastPop() {
+	last := c.scopes[c.scopeIndex].lastInstruction
+	previous := c.scopes[c.scopeIndex].previousInstruction
+
+	c.scopes[c.scopeIndex].instructions = c.currentInstructions()[:last.Position]
+	c.scopes[c.scopeIndex].lastInstruction = previous
+}
+
+// changeOperand rewrites the operand of an instruction already emitted at the given position
+func (c *compiler) changeOperand(pos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[pos])
+	c.checkOperands(op, operand)
+
+	ins := code.Make(op, operand)
+
+	copy(c.currentInstructions()[pos:], ins)
+}
+
+// checkOperands records an operand too large for its instruction, e.g. a jump past 64 KB of
+// instructions or a call with more than 255 arguments, code.Make would silently truncate it
+func (c *compiler) checkOperands(op code.Opcode, operands ...int) {
+	if err := code.CheckOperands(op, operands...); err != nil && c.err == nil {
+		c.err = fmt.Errorf("%w: %v", ErrTooLarge, err)
+	}
+}
+
+func (c *compiler) enterScope() {
+	c.scopes = append(c.scopes, compilationScope{instructions: code.Instructions{}})
+	c.scopeIndex++
+
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
@@ -0,0 +1,360 @@
+package compiler_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/compiler"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// names formats n distinct identifiers and joins them with a separator, identifiers cannot contain
+// digits so the index of each one is written with letters
+func names(n int, format string, sep string) string {
+	strs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		name := ""
+		for j := i; j > 0 || name == ""; j /= 26 {
+			name = string(rune('a'+j%26)) + name
+		}
+
+		strs = append(strs, fmt.Sprintf(format, name))
+	}
+
+	return strings.Join(strs, sep)
+}
+
+// concat flattens instructions into a single instruction sequence
+func concat(instructions ...[]byte) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range instructions {
+		out = append(out, ins...)
+	}
+
+	return out
+}
+
+var _ = Describe("Compiler", func() {
+	var (
+		p parser.Parser
+		c compiler.Compiler
+	)
+
+	BeforeEach(func() {
+		p = parser.New(lexer.New())
+		c = compiler.New()
+	})
+
+	compile := func(text string) *compiler.Bytecode {
+		program, errs := p.ParseProgram(text)
+		Expect(errs).To(BeEmpty())
+		Expect(c.Compile(program)).To(Succeed())
+
+		return c.Bytecode()
+	}
+
+	Describe("Compile", func() {
+		It("infix expression", func() {
+			bytecode := compile(`1 + 2;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			).String()))
+			Expect(bytecode.Constants).To(Equal([]object.Object{object.NewInteger(1), object.NewInteger(2)}))
+		})
+
+		It("expression statements except the last one are discarded", func() {
+			bytecode := compile(`1; 2;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("if expression without alternative", func() {
+			bytecode := compile(`if (true) { 10; };`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpJump, 11),
+				// 0010
+				code.Make(code.OpNil),
+				// 0011
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("nil literal", func() {
+			bytecode := compile(`nil;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				// 0000
+				code.Make(code.OpNil),
+				// 0001
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("logical operators skip their right operand", func() {
+			bytecode := compile(`true && false || 1;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthyOrPop, 5),
+				// 0004
+				code.Make(code.OpFalse),
+				// 0005
+				code.Make(code.OpJumpTruthyOrPop, 11),
+				// 0008
+				code.Make(code.OpConstant, 0),
+				// 0011
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("interpolated string", func() {
+			bytecode := compile(`"a${1}${2}";`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpInterpolate, 3),
+				code.Make(code.OpPop),
+			).String()))
+			Expect(bytecode.Constants).To(Equal([]object.Object{object.NewString("a"), object.NewInteger(1), object.NewInteger(2)}))
+		})
+
+		It("slice expression pushes only the given indexes", func() {
+			bytecode := compile(`"abc"[:2];`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSlice, code.SliceHigh),
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("while loop with a break", func() {
+			bytecode := compile(`while (true) { break; };`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpJump, 10),
+				// 0007
+				code.Make(code.OpJump, 0),
+				// 0010
+				code.Make(code.OpNil),
+				// 0011
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("global let statements", func() {
+			bytecode := compile(`let a = 1; a;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+			).String()))
+		})
+
+		It("closures capture free variables", func() {
+			bytecode := compile(`fn(a) { fn(b) { a + b; }; };`)
+
+			inner := bytecode.Constants[0].(*object.CompiledFunc)
+			Expect(inner.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpGetFree, 0),
+				code.Make(code.OpGetLocal, 0),
+				code.Make(code.OpAdd),
+				code.Make(code.OpReturnValue),
+			).String()))
+
+			outer := bytecode.Constants[1].(*object.CompiledFunc)
+			Expect(outer.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpGetLocal, 0),
+				code.Make(code.OpClosure, 0, 1),
+				code.Make(code.OpReturnValue),
+			).String()))
+		})
+
+		It("builtin functions are referenced by name", func() {
+			bytecode := compile(`len;`)
+
+			Expect(bytecode.Instructions.String()).To(Equal(concat(
+				code.Make(code.OpGetBuiltin, 0),
+				code.Make(code.OpPop),
+			).String()))
+			Expect(bytecode.Constants).To(Equal([]object.Object{object.NewString("len")}))
+		})
+
+		DescribeTable("programs it rejects",
+			func(text string, expectedError error) {
+				program, errs := p.ParseProgram(text)
+				Expect(errs).To(BeEmpty())
+				Expect(c.Compile(program)).To(MatchError(expectedError))
+			},
+			Entry("assignment to an undeclared identifier", `x = 1;`, compiler.ErrAssignToUndeclared),
+			Entry("assignment to a builtin function", `len = 1;`, compiler.ErrAssignToUndeclared),
+			Entry("assignment to a captured variable", `fn(a) { fn() { a += 1; }; };`, compiler.ErrNotSupported),
+			Entry("try statement", `try { 1; } catch (e) { 2; };`, compiler.ErrNotSupported),
+			Entry("throw statement", `fn() { throw "x"; };`, compiler.ErrNotSupported),
+		)
+
+		DescribeTable("programs exceeding the limits of the bytecode",
+			func(text string) {
+				program, errs := p.ParseProgram(text)
+				Expect(errs).To(BeEmpty())
+				Expect(c.Compile(program)).To(MatchError(compiler.ErrTooLarge))
+			},
+			Entry("constant index above 65535", strings.Repeat("1;", 65537)),
+			Entry("jump target above 65535", "if (true) { "+strings.Repeat("true;", 33000)+" };"),
+			Entry("global index above 65535", names(65537, "let v%s = 0;", " ")),
+			Entry("local index above 255", "fn() { "+names(257, "let v%s = 0;", " ")+" };"),
+			Entry("more than 256 locals", "fn("+names(300, "p%s", ", ")+") { 0; };"),
+			Entry("more than 255 arguments", "len("+strings.Repeat("true, ", 255)+"true);"),
+			Entry("more than 255 free variables", "fn("+names(256, "p%s", ", ")+") { fn() { ["+names(256, "p%s", ", ")+"]; }; };"),
+		)
+
+		It("empty statement", func() {
+			program, errs := p.ParseProgram(`;`)
+			Expect(errs).To(BeEmpty())
+			Expect(c.Compile(program)).To(MatchError(compiler.ErrEmptyNodeInput))
+		})
+	})
+
+	Describe("Bytecode", func() {
+		It("round trips through the binary encoding", func() {
+			bytecode := compile(`let add = fn(x, y) { let z = x + y; z; }; add(1, -2); "hello"; 123456789012345678901234567890; 2.5e-3;`)
+
+			data, err := bytecode.MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiler.IsBytecode(data)).To(BeTrue())
+
+			decoded := &compiler.Bytecode{}
+			Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+			Expect(decoded).To(Equal(bytecode))
+		})
+
+		It("rejects data without the file signature", func() {
+			decoded := &compiler.Bytecode{}
+			Expect(decoded.UnmarshalBinary([]byte("let a = 1;"))).To(MatchError(compiler.ErrInvalidBytecode))
+		})
+
+		It("rejects truncated data", func() {
+			data, err := compile(`"hello";`).MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded := &compiler.Bytecode{}
+			Expect(decoded.UnmarshalBinary(data[:len(data)-2])).To(MatchError(compiler.ErrInvalidBytecode))
+		})
+
+		It("rejects every truncation of a file", func() {
+			data, err := compile(`let f = fn(x) { if (x) { len("a"); } else { [x]; }; }; f(1);`).MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+
+			for n := range data {
+				decoded := &compiler.Bytecode{}
+				Expect(decoded.UnmarshalBinary(data[:n])).To(MatchError(compiler.ErrInvalidBytecode))
+			}
+		})
+
+		DescribeTable("rejects instructions the VM cannot run",
+			func(bytecode *compiler.Bytecode) {
+				data, err := bytecode.MarshalBinary()
+				Expect(err).ToNot(HaveOccurred())
+
+				decoded := &compiler.Bytecode{}
+				Expect(decoded.UnmarshalBinary(data)).To(MatchError(compiler.ErrInvalidBytecode))
+			},
+			Entry("truncated operands", &compiler.Bytecode{
+				Instructions: code.Make(code.OpConstant, 0)[:2],
+				Constants:    []object.Object{object.NewInteger(1)},
+			}),
+			Entry("undefined opcode", &compiler.Bytecode{
+				Instructions: code.Instructions{255},
+			}),
+			Entry("constant out of range", &compiler.Bytecode{
+				Instructions: code.Make(code.OpConstant, 1),
+				Constants:    []object.Object{object.NewInteger(1)},
+			}),
+			Entry("closure of a constant that is not a function", &compiler.Bytecode{
+				Instructions: code.Make(code.OpClosure, 0, 0),
+				Constants:    []object.Object{object.NewString("f")},
+			}),
+			Entry("builtin name that is not a string", &compiler.Bytecode{
+				Instructions: code.Make(code.OpGetBuiltin, 0),
+				Constants:    []object.Object{object.NewInteger(1)},
+			}),
+			Entry("local outside of a function", &compiler.Bytecode{
+				Instructions: code.Make(code.OpGetLocal, 0),
+			}),
+			Entry("jump into the operands of an instruction", &compiler.Bytecode{
+				Instructions: concat(code.Make(code.OpJump, 1), code.Make(code.OpPop)),
+			}),
+			Entry("invalid function instructions", &compiler.Bytecode{
+				Instructions: code.Make(code.OpClosure, 0, 0),
+				Constants: []object.Object{
+					object.NewCompiledFunc(code.Make(code.OpGetLocal, 1), 1, 1),
+				},
+			}),
+		)
+	})
+
+	Describe("SymbolTable", func() {
+		It("resolves globals, locals and free variables", func() {
+			global := compiler.NewSymbolTable()
+			a := global.Define("a")
+			Expect(a).To(Equal(compiler.Symbol{Name: "a", Scope: compiler.GlobalScope, Index: 0}))
+
+			local := compiler.NewEnclosedSymbolTable(global)
+			b := local.Define("b")
+			Expect(b).To(Equal(compiler.Symbol{Name: "b", Scope: compiler.LocalScope, Index: 0}))
+
+			nested := compiler.NewEnclosedSymbolTable(local)
+			symbol, ok := nested.Resolve("a")
+			Expect(ok).To(BeTrue())
+			Expect(symbol).To(Equal(a))
+
+			symbol, ok = nested.Resolve("b")
+			Expect(ok).To(BeTrue())
+			Expect(symbol).To(Equal(compiler.Symbol{Name: "b", Scope: compiler.FreeScope, Index: 0}))
+			Expect(nested.FreeSymbols).To(Equal([]compiler.Symbol{b}))
+
+			_, ok = nested.Resolve("c")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("redefining a name reuses its slot", func() {
+			global := compiler.NewSymbolTable()
+			Expect(global.Define("a")).To(Equal(global.Define("a")))
+			Expect(global.NumDefinitions()).To(Equal(1))
+		})
+	})
+})
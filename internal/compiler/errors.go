@@ -0,0 +1,16 @@
+package compiler
+
+import (
+	"errors"
+)
+
+var (
+	ErrEmptyNodeInput         = errors.New("empty node input")
+	ErrUnexpectedNodeType     = errors.New("unexpected node type")
+	ErrUnexpectedOperatorType = errors.New("unexpected operator type")
+	ErrInvalidBytecode        = errors.New("invalid bytecode")
+	ErrUnsupportedConstant    = errors.New("unsupported constant type")
+	ErrAssignToUndeclared     = errors.New("assignment to undeclared identifier")
+	ErrNotSupported           = errors.New("not supported by the compiler")
+	ErrTooLarge               = errors.New("program too large for bytecode")
+)
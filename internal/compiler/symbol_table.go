@@ -0,0 +1,141 @@
+package compiler
+
+type SymbolScope string
+
+const (
+	GlobalScope   SymbolScope = "GLOBAL"
+	LocalScope    SymbolScope = "LOCAL"
+	BuiltinScope  SymbolScope = "BUILTIN"
+	FreeScope     SymbolScope = "FREE"
+	FunctionScope SymbolScope = "FUNCTION"
+)
+
+// Symbol is a name bound in a scope together with the slot it occupies
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+// SymbolTable resolves names to symbols at compile time, each function body gets its own table
+type SymbolTable struct {
+	Outer *SymbolTable
+	// free variables captured from enclosing scopes, in the order they were resolved
+	FreeSymbols []Symbol
+
+	store          map[string]Symbol
+	numDefinitions int
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		FreeSymbols: []Symbol{},
+		store:       make(map[string]Symbol),
+	}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+
+	return s
+}
+
+// Define binds a name in the current scope, redefining a name reuses its slot
+func (s *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := s.store[name]; ok && (symbol.Scope == GlobalScope || symbol.Scope == LocalScope) {
+		return symbol
+	}
+
+	symbol := Symbol{
+		Name:  name,
+		Index: s.numDefinitions,
+		Scope: LocalScope,
+	}
+
+	if s.Outer == nil {
+		symbol.Scope = GlobalScope
+	}
+
+	s.store[name] = symbol
+	s.numDefinitions++
+
+	return symbol
+}
+
+// DefineFunctionName binds the name of the function being compiled so that it can refer to itself
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{
+		Name:  name,
+		Index: 0,
+		Scope: FunctionScope,
+	}
+
+	s.store[name] = symbol
+
+	return symbol
+}
+
+// DefineBuiltin binds the name of a builtin function, the index is the constant holding its name
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{
+		Name:  name,
+		Index: index,
+		Scope: BuiltinScope,
+	}
+
+	s.store[name] = symbol
+
+	return symbol
+}
+
+// Resolve looks up a name from the current scope outwards,
+// locals of enclosing functions are turned into free variables of the current one
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	if symbol, ok := s.store[name]; ok {
+		return symbol, true
+	}
+
+	if s.Outer == nil {
+		return Symbol{}, false
+	}
+
+	symbol, ok := s.Outer.Resolve(name)
+	if !ok {
+		return symbol, false
+	}
+
+	if symbol.Scope == GlobalScope || symbol.Scope == BuiltinScope {
+		return symbol, true
+	}
+
+	return s.defineFree(symbol), true
+}
+
+// NumDefinitions returns the number of slots used by the current scope
+func (s *SymbolTable) NumDefinitions() int {
+	return s.numDefinitions
+}
+
+// global returns the outermost symbol table
+func (s *SymbolTable) global() *SymbolTable {
+	for s.Outer != nil {
+		s = s.Outer
+	}
+
+	return s
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{
+		Name:  original.Name,
+		Index: len(s.FreeSymbols) - 1,
+		Scope: FreeScope,
+	}
+
+	s.store[original.Name] = symbol
+
+	return symbol
+}
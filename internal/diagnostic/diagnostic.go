@@ -0,0 +1,91 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aden-q/monkey/internal/token"
+)
+
+// Spanned is implemented by errors pointing at a part of the source text,
+// e.g. parser and evaluator errors
+type Spanned interface {
+	error
+	// Span returns the position of the first character and the position immediately after the offending part
+	Span() (token.Position, token.Position)
+}
+
+// Traced is implemented by errors carrying a Monkey stack trace, e.g. evaluator errors
+type Traced interface {
+	error
+	// StackTrace returns the lines of the stack trace, the innermost frame first
+	StackTrace() []string
+}
+
+// WriteStackTrace writes the Monkey stack trace of an error one frame per line,
+// every line is prefixed with indent. Nothing is written when the error does not carry a stack trace
+func WriteStackTrace(w io.Writer, indent string, err error) {
+	var traced Traced
+	if !errors.As(err, &traced) {
+		return
+	}
+
+	for _, line := range traced.StackTrace() {
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
+
+// WriteSnippet writes the source line an error points at followed by a line with a caret under the offending part,
+// every line is prefixed with indent. Nothing is written when the error does not carry a position
+func WriteSnippet(w io.Writer, indent string, src string, err error) {
+	var spanned Spanned
+	if !errors.As(err, &spanned) {
+		return
+	}
+
+	pos, end := spanned.Span()
+	if !pos.IsValid() || pos.Offset > len(src) {
+		return
+	}
+
+	// locate the line containing the start of the span
+	lineStart := strings.LastIndexByte(src[:pos.Offset], '\n') + 1
+	lineEnd := len(src)
+	if i := strings.IndexByte(src[pos.Offset:], '\n'); i >= 0 {
+		lineEnd = pos.Offset + i
+	}
+
+	line := strings.TrimRight(src[lineStart:lineEnd], "\r")
+
+	fmt.Fprintf(w, "%s%s\n", indent, line)
+	fmt.Fprintf(w, "%s%s%s\n", indent, padding(src[lineStart:pos.Offset]), marker(src, pos, end, lineEnd))
+}
+
+// padding returns the white spaces aligning the caret with the text preceding it, tabs are kept as is
+func padding(prefix string) string {
+	builder := strings.Builder{}
+
+	for _, ch := range prefix {
+		if ch == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+
+	return builder.String()
+}
+
+// marker returns a caret under the first character of the span, followed by tildes
+// under the rest of it as long as it stays on the same line
+func marker(src string, pos, end token.Position, lineEnd int) string {
+	length := 1
+	if end.IsValid() && end.Offset > pos.Offset {
+		length = utf8.RuneCountInString(src[pos.Offset:min(end.Offset, lineEnd)])
+	}
+
+	return "^" + strings.Repeat("~", max(length-1, 0))
+}
@@ -0,0 +1,13 @@
+package diagnostic_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiagnostic(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diagnostic Suite")
+}
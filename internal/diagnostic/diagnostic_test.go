@@ -0,0 +1,73 @@
+package diagnostic_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/aden-q/monkey/internal/diagnostic"
+	"github.com/aden-q/monkey/internal/evaluator"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diagnostic", func() {
+	var (
+		out *bytes.Buffer
+		p   parser.Parser
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		p = parser.New(lexer.New())
+	})
+
+	Describe("WriteStackTrace", func() {
+		It("writes the frames of an evaluator error", func() {
+			src := "let f = fn(x) { x + foo; };\nf(1);"
+			program, errs := p.ParseProgram(src)
+			Expect(errs).To(BeEmpty())
+
+			_, err := evaluator.New(object.NewEnvironment()).Eval(program)
+			Expect(err).To(HaveOccurred())
+
+			diagnostic.WriteStackTrace(out, "\t", err)
+			Expect(out.String()).To(Equal("\tat f (1:21)\n\tat <main> (2:1)\n"))
+		})
+
+		It("writes nothing for errors without a stack trace", func() {
+			diagnostic.WriteStackTrace(out, "", errors.New("boom"))
+			Expect(out.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("WriteSnippet", func() {
+		It("points at the offending token of a parser error", func() {
+			src := "let a = 1;\nlet = 5;\n"
+			_, errs := p.ParseProgram(src)
+			Expect(errs).ToNot(BeEmpty())
+
+			diagnostic.WriteSnippet(out, "", src, errs[0])
+			Expect(out.String()).To(Equal("let = 5;\n    ^\n"))
+		})
+
+		It("underlines the node of an evaluator error", func() {
+			src := "let a = 1;\n\ta + foo;"
+			program, errs := p.ParseProgram(src)
+			Expect(errs).To(BeEmpty())
+
+			_, err := evaluator.New(object.NewEnvironment()).Eval(program)
+			Expect(err).To(HaveOccurred())
+
+			diagnostic.WriteSnippet(out, "> ", src, err)
+			Expect(out.String()).To(Equal("> \ta + foo;\n> \t    ^~~\n"))
+		})
+
+		It("writes nothing for errors without a position", func() {
+			diagnostic.WriteSnippet(out, "", "1 + 1;", errors.New("boom"))
+			Expect(out.String()).To(BeEmpty())
+		})
+	})
+})
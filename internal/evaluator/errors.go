@@ -2,16 +2,141 @@ package evaluator
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/aden-q/monkey/internal/ast"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/token"
 )
 
 var (
 	ErrEmptyNodeInput         = errors.New("empty node input")
 	ErrUnexpectedNodeType     = errors.New("unexpected node type")
 	ErrUnexpectedObjectType   = errors.New("unexpected object type")
-	ErrUnexpectedOperatorType = errors.New("unexpected operator type")
+	ErrUnexpectedOperatorType = object.ErrUnexpectedOperatorType
 	ErrIdentifierNotFound     = errors.New("identifier not found")
 	ErrNotAFunction           = errors.New("not a function")
 	ErrIndexOutOfRange        = errors.New("index out of range")
 	ErrUnhashableType         = errors.New("unhashable type")
 	ErrKeyNotFound            = errors.New("key not found")
+	ErrNotIterable            = object.ErrNotIterable
+	ErrAssignToUndeclared     = errors.New("assignment to undeclared identifier")
+	ErrDivisionByZero         = object.ErrDivisionByZero
+	ErrInvalidShiftCount      = object.ErrInvalidShiftCount
+	ErrStackOverflow          = object.ErrStackOverflow
 )
+
+// errorKinds names the kind of the error objects runtime errors are converted to when they are caught,
+// errors missing from the table are of kind RuntimeError
+var errorKinds = map[error]string{
+	ErrIdentifierNotFound:             "NameError",
+	ErrAssignToUndeclared:             "NameError",
+	ErrIndexOutOfRange:                "IndexError",
+	ErrKeyNotFound:                    "KeyError",
+	ErrUnexpectedObjectType:           "TypeError",
+	ErrUnexpectedOperatorType:         "TypeError",
+	ErrNotAFunction:                   "TypeError",
+	ErrUnhashableType:                 "TypeError",
+	ErrNotIterable:                    "TypeError",
+	ErrDivisionByZero:                 "ZeroDivisionError",
+	ErrInvalidShiftCount:              "ValueError",
+	ErrStackOverflow:                  "RecursionError",
+	object.ErrIntegerTooLarge:         "OverflowError",
+	object.ErrWrongNumberArguments:    "TypeError",
+	object.ErrUnsupportedArgumentType: "TypeError",
+	object.ErrInvalidArgument:         "ValueError",
+	object.ErrEmptyArray:              "IndexError",
+}
+
+// Exception is raised by a throw statement, it carries the thrown value until a catch clause handles it
+type Exception struct {
+	Value object.Object
+}
+
+func (e *Exception) Error() string {
+	return "uncaught exception: " + e.Value.Inspect()
+}
+
+// Error is a runtime error, it wraps the underlying error
+// together with the node being evaluated when it happened
+type Error struct {
+	// the underlying error, either one of the sentinel errors above or one raised by a builtin function
+	Err error
+	// the innermost node that failed to evaluate
+	Node ast.Node
+	// the Monkey stack trace at the time of the error, the innermost frame first
+	Stack []Frame
+}
+
+func (e *Error) Error() string {
+	return e.Node.Pos().String() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the part of the source text the error points at
+func (e *Error) Span() (token.Position, token.Position) {
+	return e.Node.Pos(), e.Node.End()
+}
+
+// StackTrace returns the lines of the Monkey stack trace, the innermost frame first
+func (e *Error) StackTrace() []string {
+	return formatStack(e.Stack)
+}
+
+// the number of times a frame repeated by recursion is listed before the rest of the repetitions are summarized
+const maxRepeatedFrames = 3
+
+// formatStack formats the frames of a stack trace, one line per frame,
+// consecutive identical frames beyond the first few are summarized in a single line
+func formatStack(stack []Frame) []string {
+	lines := make([]string, 0, len(stack))
+	for i := 0; i < len(stack); {
+		repeated := 1
+		for i+repeated < len(stack) && stack[i+repeated] == stack[i] {
+			repeated++
+		}
+
+		for j := 0; j < min(repeated, maxRepeatedFrames); j++ {
+			lines = append(lines, stack[i].String())
+		}
+
+		if repeated > maxRepeatedFrames {
+			lines = append(lines, fmt.Sprintf("[previous frame repeated %d more times]", repeated-maxRepeatedFrames))
+		}
+
+		i += repeated
+	}
+
+	return lines
+}
+
+// errorValue converts an error handled by a catch clause to the value bound to its parameter,
+// thrown values are bound as is while other runtime errors are converted to error objects
+func errorValue(err error) object.Object {
+	var exception *Exception
+	if errors.As(err, &exception) {
+		return exception.Value
+	}
+
+	message := err.Error()
+	stack := []string{}
+
+	var evalErr *Error
+	if errors.As(err, &evalErr) {
+		message = evalErr.Err.Error()
+		stack = evalErr.StackTrace()
+	}
+
+	kind := "RuntimeError"
+	for sentinel, name := range errorKinds {
+		if errors.Is(err, sentinel) {
+			kind = name
+			break
+		}
+	}
+
+	return object.NewError(kind, message, stack...)
+}
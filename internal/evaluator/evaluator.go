@@ -1,10 +1,18 @@
 package evaluator
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/aden-q/monkey/internal/ast"
 	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/token"
 )
 
 // interface compliance check
@@ -13,25 +21,75 @@ var _ Evaluator = (*evaluator)(nil)
 // An interpreter/evaluator interface
 type Evaluator interface {
 	Eval(node ast.Node) (object.Object, error)
+	// EvalContext evaluates a node, the evaluation stops with the context error once the context is done
+	EvalContext(ctx context.Context, node ast.Node) (object.Object, error)
+	// Call calls a function or builtin function object with the given arguments
+	Call(ctx context.Context, fn object.Object, args ...object.Object) (object.Object, error)
 	extendFunctionEnv(fn *object.Func, args []object.Object)
 }
 
 type evaluator struct {
+	// checked between loop iterations and function calls, so that long running programs can be stopped
+	ctx context.Context
 	env object.Environment
+	// where builtins such as print write their output
+	out io.Writer
+	// the builtin functions, looked up when a name is not bound in the environment
+	builtins map[string]object.BuiltinFunc
+	// the function calls in progress, shared with the evaluators of the called functions
+	stack *callStack
 }
 
 func New(env object.Environment) Evaluator {
+	return NewWithOutput(env, os.Stdout)
+}
+
+// NewWithOutput creates an evaluator whose builtins write their output to the given writer
+func NewWithOutput(env object.Environment, out io.Writer) Evaluator {
+	return NewWithBuiltins(env, out, object.BuiltinFuncs)
+}
+
+// NewWithBuiltins creates an evaluator with its own set of builtin functions
+func NewWithBuiltins(env object.Environment, out io.Writer, builtins map[string]object.BuiltinFunc) Evaluator {
 	return &evaluator{
-		env: env,
+		ctx:      context.Background(),
+		env:      env,
+		out:      out,
+		builtins: builtins,
+		stack:    &callStack{},
 	}
 }
 
+func (e *evaluator) EvalContext(ctx context.Context, node ast.Node) (object.Object, error) {
+	ctxEvaluator := *e
+	ctxEvaluator.ctx = ctx
+
+	return ctxEvaluator.Eval(node)
+}
+
+func (e *evaluator) Call(ctx context.Context, fn object.Object, args ...object.Object) (object.Object, error) {
+	ctxEvaluator := *e
+	ctxEvaluator.ctx = ctx
+
+	return ctxEvaluator.applyFunc(fn, args, token.Position{})
+}
+
 // Eval evaluate an AST node recursively
 func (e *evaluator) Eval(node ast.Node) (object.Object, error) {
 	if node == nil {
 		return object.NIL, ErrEmptyNodeInput
 	}
 
+	obj, err := e.eval(node)
+	if err != nil {
+		return obj, e.wrapError(node, err)
+	}
+
+	return obj, nil
+}
+
+// eval evaluates a single node, errors are attached to the node by Eval
+func (e *evaluator) eval(node ast.Node) (object.Object, error) {
 	// explicitly polymorphic switch statement
 	switch node := node.(type) {
 	// evaluate the program
@@ -46,21 +104,45 @@ func (e *evaluator) Eval(node ast.Node) (object.Object, error) {
 		return e.evalLetStatement(node)
 	case *ast.ReturnStatement:
 		return e.evalReturnStatement(node)
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node)
+	case *ast.ForStatement:
+		return e.evalForStatement(node)
+	case *ast.BreakStatement:
+		return object.BREAK, nil
+	case *ast.ContinueStatement:
+		return object.CONTINUE, nil
+	case *ast.TryStatement:
+		return e.evalTryStatement(node)
+	case *ast.ThrowStatement:
+		return e.evalThrowStatement(node)
 	// evaluate expressions
 	case *ast.IdentifierExpression:
 		return e.evalIdentifierExpression(node)
 	case *ast.IntegerExpression:
+		if node.Big != nil {
+			return object.NewBigInteger(node.Big), nil
+		}
+
 		return object.NewInteger(node.Value), nil
+	case *ast.FloatExpression:
+		return object.NewFloat(node.Value), nil
 	case *ast.BooleanExpression:
 		return booleanConv(node.Value), nil
+	case *ast.NilExpression:
+		return object.NIL, nil
 	case *ast.StringExpression:
 		return object.NewString(node.Value), nil
+	case *ast.InterpolatedStringExpression:
+		return e.evalInterpolatedStringExpression(node)
 	case *ast.ArrayExpression:
 		return e.evalArrayExpression(node)
 	case *ast.HashExpression:
 		return e.evalHashExpression(node)
 	case *ast.IndexExpression:
 		return e.evalIndexExpression(node)
+	case *ast.SliceExpression:
+		return e.evalSliceExpression(node)
 	case *ast.IfExpression:
 		return e.evalIfExpression(node)
 	case *ast.FuncExpression:
@@ -71,12 +153,29 @@ func (e *evaluator) Eval(node ast.Node) (object.Object, error) {
 		return e.evalPrefixExpression(node)
 	case *ast.InfixExpression:
 		return e.evalInfixExpression(node)
+	case *ast.AssignExpression:
+		return e.evalAssignExpression(node)
 	}
 
 	// no match, unexpected path
 	return object.NIL, ErrUnexpectedNodeType
 }
 
+// wrapError attaches a node and the current stack trace to an error,
+// errors already attached to a nested node are kept as is
+func (e *evaluator) wrapError(node ast.Node, err error) error {
+	var evalErr *Error
+	if errors.As(err, &evalErr) || !node.Pos().IsValid() {
+		return err
+	}
+
+	return &Error{
+		Err:   err,
+		Node:  node,
+		Stack: e.stack.trace(node.Pos()),
+	}
+}
+
 func (e *evaluator) evalStatements(stmts []ast.Statement) (object.Object, error) {
 	var result object.Object = object.NIL
 	var err error
@@ -88,8 +187,9 @@ func (e *evaluator) evalStatements(stmts []ast.Statement) (object.Object, error)
 			return object.NIL, err
 		}
 
-		// short-circuit return statement
-		if result.Type() == object.RETURN_VALUE_OBJ {
+		// short-circuit return, break and continue statements
+		switch result.Type() {
+		case object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
 			return result, nil
 		}
 	}
@@ -103,6 +203,13 @@ func (e *evaluator) evalLetStatement(stmt *ast.LetStatement) (object.Object, err
 		return object.NIL, err
 	}
 
+	// a function literal is named after the identifier it is bound to, it shows up in stack traces
+	if fn, ok := val.(*object.Func); ok {
+		if _, ok := stmt.Value.(*ast.FuncExpression); ok {
+			fn.Name = stmt.Identifier.Value
+		}
+	}
+
 	// bind the evaluated value to the environment
 	e.env.Set(stmt.Identifier.Value, val)
 
@@ -118,12 +225,120 @@ func (e *evaluator) evalReturnStatement(stmt *ast.ReturnStatement) (object.Objec
 	return object.NewReturnValue(val), nil
 }
 
+func (e *evaluator) evalWhileStatement(ws *ast.WhileStatement) (object.Object, error) {
+	for {
+		condition, err := e.Eval(ws.Condition)
+		if err != nil {
+			return object.NIL, err
+		}
+
+		if !condition.IsTruthy() {
+			return object.NIL, nil
+		}
+
+		result, done, err := e.evalLoopBody(ws.Body)
+		if done || err != nil {
+			return result, err
+		}
+	}
+}
+
+func (e *evaluator) evalForStatement(fs *ast.ForStatement) (object.Object, error) {
+	iterable, err := e.Eval(fs.Iterable)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	elements, ok := object.Iterate(iterable)
+	if !ok {
+		return object.NIL, ErrNotIterable
+	}
+
+	for _, element := range elements {
+		// the loop variable is bound in the enclosing scope, the same way let does
+		e.env.Set(fs.Identifier.Value, element)
+
+		result, done, err := e.evalLoopBody(fs.Body)
+		if done || err != nil {
+			return result, err
+		}
+	}
+
+	return object.NIL, nil
+}
+
+// evalLoopBody evaluates a single iteration of a loop and reports whether the loop terminates,
+// a return value is propagated to the caller while a break terminates the loop with nil
+func (e *evaluator) evalLoopBody(body *ast.BlockStatement) (object.Object, bool, error) {
+	if err := e.ctx.Err(); err != nil {
+		return object.NIL, true, err
+	}
+
+	result, err := e.Eval(body)
+	if err != nil {
+		return object.NIL, true, err
+	}
+
+	switch result.Type() {
+	case object.RETURN_VALUE_OBJ:
+		return result, true, nil
+	case object.BREAK_OBJ:
+		return object.NIL, true, nil
+	default:
+		return object.NIL, false, nil
+	}
+}
+
+func (e *evaluator) evalTryStatement(ts *ast.TryStatement) (object.Object, error) {
+	result, err := e.Eval(ts.Block)
+
+	// a program cannot catch being stopped by its context
+	if err != nil && ts.Catch != nil && e.ctx.Err() == nil {
+		// the caught error is bound in the enclosing scope, the same way let does
+		e.env.Set(ts.Parameter.Value, errorValue(err))
+		result, err = e.Eval(ts.Catch)
+	}
+
+	if ts.Finally != nil {
+		finallyResult, finallyErr := e.Eval(ts.Finally)
+		if finallyErr != nil {
+			return object.NIL, finallyErr
+		}
+
+		// return, break and continue in the finally clause override the outcome of the other clauses
+		switch finallyResult.Type() {
+		case object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+			return finallyResult, nil
+		}
+	}
+
+	if err != nil {
+		return object.NIL, err
+	}
+
+	return result, nil
+}
+
+func (e *evaluator) evalThrowStatement(ts *ast.ThrowStatement) (object.Object, error) {
+	val, err := e.Eval(ts.Value)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	// errors created by programs are located where they are thrown
+	if errObj, ok := val.(*object.Error); ok && len(errObj.Stack) == 0 {
+		errObj.Stack = formatStack(e.stack.trace(ts.Pos()))
+	}
+
+	return object.NIL, &Exception{Value: val}
+}
+
 func (e *evaluator) evalIdentifierExpression(ie *ast.IdentifierExpression) (object.Object, error) {
 	if val, ok := e.env.Get(ie.Value); ok {
 		return val, nil
 	}
 
-	if builtinFunc, ok := object.BuiltinFuncs[ie.Value]; ok {
+	if builtinFunc, ok := e.builtins[ie.Value]; ok {
 		return builtinFunc, nil
 	}
 
@@ -185,14 +400,27 @@ func (e *evaluator) evalIndexExpression(ae *ast.IndexExpression) (object.Object,
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		array := left.(*object.Array)
-		idx := index.(*object.Integer).Value
+		integer := index.(*object.Integer)
+		idx := integer.Value
 		maxIdx := int64(len(array.Elements) - 1)
 
-		if idx < 0 || idx > maxIdx {
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
 			return object.NIL, ErrIndexOutOfRange
 		}
 
 		return array.Elements[idx], nil
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		// strings are indexed by character, not by byte
+		runes := []rune(left.(*object.String).Value)
+		integer := index.(*object.Integer)
+		idx := integer.Value
+		maxIdx := int64(len(runes) - 1)
+
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
+			return object.NIL, ErrIndexOutOfRange
+		}
+
+		return object.NewString(string(runes[idx])), nil
 	case left.Type() == object.HASH_OBJ:
 		hash := left.(*object.Hash)
 
@@ -207,9 +435,74 @@ func (e *evaluator) evalIndexExpression(ae *ast.IndexExpression) (object.Object,
 		}
 
 		return object.NIL, ErrKeyNotFound
+	case left.Type() == object.ERROR_OBJ && index.Type() == object.STRING_OBJ:
+		if val, ok := left.(*object.Error).Field(index.(*object.String).Value); ok {
+			return val, nil
+		}
+
+		return object.NIL, ErrKeyNotFound
+	default:
+		return object.NIL, ErrUnexpectedObjectType
+	}
+}
+
+// evalSliceExpression evaluates a[low:high] on an array or a string, negative indexes count from the end
+// and out of range indexes are clamped the same way as the slice builtin
+func (e *evaluator) evalSliceExpression(se *ast.SliceExpression) (object.Object, error) {
+	left, err := e.Eval(se.Left)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	var length int64
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = int64(len(left.Elements))
+	case *object.String:
+		length = int64(utf8.RuneCountInString(left.Value))
 	default:
 		return object.NIL, ErrUnexpectedObjectType
 	}
+
+	low, err := e.evalSliceIndex(se.Low, 0)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	high, err := e.evalSliceIndex(se.High, length)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	if array, ok := left.(*object.Array); ok {
+		return array.Slice(low, high), nil
+	}
+
+	return left.(*object.String).Slice(low, high), nil
+}
+
+// evalSliceIndex evaluates a slice index, which is value when it is omitted
+func (e *evaluator) evalSliceIndex(node ast.Expression, value int64) (int64, error) {
+	if node == nil {
+		return value, nil
+	}
+
+	index, err := e.Eval(node)
+	if err != nil {
+		return 0, err
+	}
+
+	integer, ok := index.(*object.Integer)
+	if !ok {
+		return 0, ErrUnexpectedObjectType
+	}
+
+	if integer.IsBig() {
+		return 0, ErrIndexOutOfRange
+	}
+
+	return integer.Value, nil
 }
 
 func (e *evaluator) evalIfExpression(ie *ast.IfExpression) (object.Object, error) {
@@ -251,14 +544,33 @@ func (e *evaluator) evalCallExpression(ce *ast.CallExpression) (object.Object, e
 	}
 
 	// call the function with the given arguments
-	return applyFunc(function, args, e.env)
+	return e.applyFunc(function, args, ce.Pos())
 }
 
-func applyFunc(fn object.Object, args []object.Object, env object.Environment) (object.Object, error) {
+func (e *evaluator) applyFunc(fn object.Object, args []object.Object, callSite token.Position) (object.Object, error) {
 	switch fn := fn.(type) {
 	case *object.Func:
-		// create a new environment for the function scope
-		funcEvaluator := New(object.NewClosureEnvironment(env))
+		if err := e.ctx.Err(); err != nil {
+			return object.NIL, err
+		}
+
+		if len(args) != len(fn.Parameters) {
+			return object.NIL, object.ErrWrongNumberArguments
+		}
+
+		if err := e.stack.push(fn.Name, callSite); err != nil {
+			return object.NIL, err
+		}
+		defer e.stack.pop()
+
+		// create a new scope for the call, enclosed by the scope the function is defined in
+		funcEvaluator := &evaluator{
+			ctx:      e.ctx,
+			env:      object.NewEnclosedEnvironment(fn.Env),
+			out:      e.out,
+			builtins: e.builtins,
+			stack:    e.stack,
+		}
 		// extend the closure environment with arguments passed to the function
 		funcEvaluator.extendFunctionEnv(fn, args)
 
@@ -273,7 +585,15 @@ func applyFunc(fn object.Object, args []object.Object, env object.Environment) (
 
 		return val, nil
 	case object.BuiltinFunc:
-		return fn(args...)
+		ctx := &object.CallContext{
+			Out: e.out,
+			// functions called back by the builtin are reported at the call site of the builtin
+			Call: func(callee object.Object, args ...object.Object) (object.Object, error) {
+				return e.applyFunc(callee, args, callSite)
+			},
+		}
+
+		return fn(ctx, args...)
 	default:
 		return object.NIL, ErrNotAFunction
 	}
@@ -296,28 +616,38 @@ func (e *evaluator) evalPrefixExpression(pe *ast.PrefixExpression) (object.Objec
 		return e.evalBangOperatorExpression(operandObj)
 	case "-":
 		return e.evalMinuxPrefixOperatorExpression(operandObj)
+	case "~":
+		return e.evalTildePrefixOperatorExpression(operandObj)
 	default:
 		return object.NIL, nil
 	}
 }
 
-// evalBangOperatorExpression evaluates a prefix expression with a '!' token as the prefix
+// evalBangOperatorExpression evaluates a prefix expression with a '!' token as the prefix,
+// it negates the truthiness of the operand, the same truthiness used by conditions
 func (e *evaluator) evalBangOperatorExpression(o object.Object) (object.Object, error) {
-	switch o {
-	case object.FALSE, object.NewInteger(0):
-		return object.TRUE, nil
-	default:
-		return object.FALSE, nil
-	}
+	return booleanConv(!o.IsTruthy()), nil
 }
 
 // evalMinuxPrefixOperatorExpression evaluates a prefix expression with a '-' token as the prefix
 func (e *evaluator) evalMinuxPrefixOperatorExpression(o object.Object) (object.Object, error) {
-	if o.Type() != object.INTEGER_OBJ {
+	negated, ok := object.Negate(o)
+	if !ok {
+		return object.NIL, ErrUnexpectedObjectType
+	}
+
+	return negated, nil
+}
+
+// evalTildePrefixOperatorExpression evaluates a prefix expression with a '~' token as the prefix,
+// it flips all the bits of an integer, i.e. ~x == -x - 1
+func (e *evaluator) evalTildePrefixOperatorExpression(o object.Object) (object.Object, error) {
+	flipped, ok := object.BitNot(o)
+	if !ok {
 		return object.NIL, ErrUnexpectedObjectType
 	}
 
-	return object.NewInteger(-o.(*object.Integer).Value), nil
+	return flipped, nil
 }
 
 // evalInfixExpression evaluates an infix expression
@@ -327,20 +657,41 @@ func (e *evaluator) evalInfixExpression(ie *ast.InfixExpression) (object.Object,
 		return object.NIL, err
 	}
 
+	// logical operators short-circuit and evaluate to the operand that decides the result
+	switch ie.Operator {
+	case "&&":
+		if !leftOperandObj.IsTruthy() {
+			return leftOperandObj, nil
+		}
+
+		return e.Eval(ie.RightOperand)
+	case "||":
+		if leftOperandObj.IsTruthy() {
+			return leftOperandObj, nil
+		}
+
+		return e.Eval(ie.RightOperand)
+	}
+
 	rightOperandObj, err := e.Eval(ie.RightOperand)
 	if err != nil {
 		return object.NIL, err
 	}
 
+	return e.evalInfixOperation(ie.Operator, leftOperandObj, rightOperandObj)
+}
+
+// evalInfixOperation applies an infix operator to two evaluated operands
+func (e *evaluator) evalInfixOperation(operator string, leftOperandObj, rightOperandObj object.Object) (object.Object, error) {
 	switch {
-	case leftOperandObj.Type() == object.INTEGER_OBJ && rightOperandObj.Type() == object.INTEGER_OBJ:
-		return e.evalIntegerInfixExpression(ie.Operator, leftOperandObj.(*object.Integer), rightOperandObj.(*object.Integer))
+	case object.IsNumber(leftOperandObj) && object.IsNumber(rightOperandObj):
+		return object.NumberInfix(operator, leftOperandObj, rightOperandObj)
 	case leftOperandObj.Type() == object.STRING_OBJ && rightOperandObj.Type() == object.STRING_OBJ:
-		return e.evalStringInfixExpression(ie.Operator, leftOperandObj.(*object.String), rightOperandObj.(*object.String))
+		return e.evalStringInfixExpression(operator, leftOperandObj.(*object.String), rightOperandObj.(*object.String))
 	// equality test
-	case ie.Operator == "==":
+	case operator == "==":
 		return booleanConv(reflect.DeepEqual(leftOperandObj, rightOperandObj)), nil
-	case ie.Operator == "!=":
+	case operator == "!=":
 		return booleanConv(!reflect.DeepEqual(leftOperandObj, rightOperandObj)), nil
 	default:
 		// TODO: check infix expressions involving boolean operands and operators that result in boolean values
@@ -348,34 +699,24 @@ func (e *evaluator) evalInfixExpression(ie *ast.InfixExpression) (object.Object,
 	}
 }
 
-// evalIntegerInfixExpression evaluates an infix expression involving two integer operands and a single operator
-func (e *evaluator) evalIntegerInfixExpression(operator string, left, right *object.Integer) (object.Object, error) {
-	leftVal, rightVal := left.Value, right.Value
+// evalInterpolatedStringExpression evaluates the interpolated expressions of a string
+// and joins their string representations with the text around them
+func (e *evaluator) evalInterpolatedStringExpression(ise *ast.InterpolatedStringExpression) (object.Object, error) {
+	builder := strings.Builder{}
 
-	switch operator {
-	case "+":
-		return object.NewInteger(leftVal + rightVal), nil
-	case "-":
-		return object.NewInteger(leftVal - rightVal), nil
-	case "*":
-		return object.NewInteger(leftVal * rightVal), nil
-	case "/":
-		return object.NewInteger(leftVal / rightVal), nil
-	case "<":
-		return booleanConv(leftVal < rightVal), nil
-	case "<=":
-		return booleanConv(leftVal <= rightVal), nil
-	case ">":
-		return booleanConv(leftVal > rightVal), nil
-	case ">=":
-		return booleanConv(leftVal >= rightVal), nil
-	case "==":
-		return booleanConv(leftVal == rightVal), nil
-	case "!=":
-		return booleanConv(leftVal != rightVal), nil
-	default:
-		return object.NIL, ErrUnexpectedOperatorType
+	for i, exp := range ise.Expressions {
+		val, err := e.Eval(exp)
+		if err != nil {
+			return object.NIL, err
+		}
+
+		builder.WriteString(ise.Strings[i])
+		builder.WriteString(val.Inspect())
 	}
+
+	builder.WriteString(ise.Strings[len(ise.Strings)-1])
+
+	return object.NewString(builder.String()), nil
 }
 
 // evalStringInfixExpression evaluates an infix expression involving two string operands and a single operator
@@ -390,6 +731,105 @@ func (e *evaluator) evalStringInfixExpression(operator string, left, right *obje
 	return object.NIL, ErrUnexpectedOperatorType
 }
 
+// evalAssignExpression evaluates an assignment to an existing binding, an array element or a hash entry.
+// Compound assignments such as += apply the operator to the current value first
+func (e *evaluator) evalAssignExpression(ae *ast.AssignExpression) (object.Object, error) {
+	switch target := ae.Target.(type) {
+	case *ast.IdentifierExpression:
+		current, ok := e.env.Get(target.Value)
+		if !ok {
+			return object.NIL, fmt.Errorf("%w: %s", ErrAssignToUndeclared, target.Value)
+		}
+
+		val, err := e.evalAssignedValue(ae, current)
+		if err != nil {
+			return object.NIL, err
+		}
+
+		e.env.Assign(target.Value, val)
+
+		return val, nil
+	case *ast.IndexExpression:
+		return e.evalIndexAssignment(ae, target)
+	default:
+		return object.NIL, ErrUnexpectedNodeType
+	}
+}
+
+func (e *evaluator) evalIndexAssignment(ae *ast.AssignExpression, target *ast.IndexExpression) (object.Object, error) {
+	left, err := e.Eval(target.Left)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	index, err := e.Eval(target.Index)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		integer := index.(*object.Integer)
+		idx := integer.Value
+		maxIdx := int64(len(array.Elements) - 1)
+
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
+			return object.NIL, ErrIndexOutOfRange
+		}
+
+		val, err := e.evalAssignedValue(ae, array.Elements[idx])
+		if err != nil {
+			return object.NIL, err
+		}
+
+		array.Elements[idx] = val
+
+		return val, nil
+	case left.Type() == object.HASH_OBJ:
+		hash := left.(*object.Hash)
+
+		// a key must be hashable in order to be used as a key in a hash object
+		hashKey, ok := index.(object.Hashable)
+		if !ok {
+			return object.NIL, ErrUnhashableType
+		}
+
+		// a plain assignment may add a new entry, a compound one needs an existing value
+		current, ok := hash.Items[hashKey.HashKey()]
+		if !ok && ae.Operator != "=" {
+			return object.NIL, ErrKeyNotFound
+		}
+
+		val, err := e.evalAssignedValue(ae, current)
+		if err != nil {
+			return object.NIL, err
+		}
+
+		hash.Items[hashKey.HashKey()] = val
+
+		return val, nil
+	default:
+		return object.NIL, ErrUnexpectedObjectType
+	}
+}
+
+// evalAssignedValue evaluates the right side of an assignment, combined with the current value
+// of the target for compound assignments
+func (e *evaluator) evalAssignedValue(ae *ast.AssignExpression, current object.Object) (object.Object, error) {
+	val, err := e.Eval(ae.Value)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	if ae.Operator == "=" {
+		return val, nil
+	}
+
+	// strip the trailing = of the compound operator to get the infix operator
+	return e.evalInfixOperation(strings.TrimSuffix(ae.Operator, "="), current, val)
+}
+
 // booleanConv converts a boolean literal to a boolean object in the object system
 func booleanConv(input bool) object.Object {
 	if input {
@@ -1,15 +1,36 @@
 package evaluator_test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
 	"github.com/aden-q/monkey/internal/ast"
 	"github.com/aden-q/monkey/internal/evaluator"
 	"github.com/aden-q/monkey/internal/lexer"
 	"github.com/aden-q/monkey/internal/object"
 	"github.com/aden-q/monkey/internal/parser"
+	"github.com/aden-q/monkey/internal/token"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+// funcOptions compares function objects by their parameters and body,
+// ignoring source positions and the environment they close over
+var funcOptions = []cmp.Option{
+	cmpopts.IgnoreTypes(token.Position{}),
+	cmpopts.IgnoreInterfaces(struct{ object.Environment }{}),
+}
+
+// bigInteger creates an integer object from a decimal literal overflowing int64
+func bigInteger(literal string) *object.Integer {
+	value, _ := new(big.Int).SetString(literal, 10)
+	return object.NewBigInteger(value)
+}
+
 var _ = Describe("Evaluator", func() {
 	var (
 		text    string
@@ -953,7 +974,7 @@ var _ = Describe("Evaluator", func() {
 
 					// evaluate the AST tree
 					obj, err := e.Eval(program)
-					Expect(err).To(Equal(expectedEvaluateError))
+					Expect(err).To(MatchError(expectedEvaluateError))
 					Expect(obj).To(Equal(expectedObject))
 				})
 			})
@@ -974,7 +995,7 @@ var _ = Describe("Evaluator", func() {
 
 					// evaluate the AST tree
 					obj, err := e.Eval(program)
-					Expect(err).To(Equal(expectedEvaluateError))
+					Expect(err).To(MatchError(expectedEvaluateError))
 					Expect(obj).To(Equal(expectedObject))
 				})
 			})
@@ -1012,7 +1033,7 @@ var _ = Describe("Evaluator", func() {
 
 					// evaluate the AST tree
 					obj, err := e.Eval(program)
-					Expect(err).To(Equal(expectedEvaluateError))
+					Expect(err).To(MatchError(expectedEvaluateError))
 					Expect(obj).To(Equal(expectedObject))
 				})
 			})
@@ -1038,7 +1059,7 @@ var _ = Describe("Evaluator", func() {
 					// evaluate the AST tree
 					obj, err := e.Eval(program)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(obj).To(Equal(expectedObject))
+					Expect(obj).To(BeComparableTo(expectedObject, funcOptions...))
 				})
 			})
 
@@ -1095,14 +1116,53 @@ var _ = Describe("Evaluator", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(obj).To(Equal(expectedObject))
 				})
+
+				It("too few arguments", func() {
+					text = `
+					let a = fn(x, y) { x * y; };
+					a(5);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrWrongNumberArguments
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("too many arguments", func() {
+					text = `
+					fn(x) { x; } (5, 6);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrWrongNumberArguments
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
 			})
 
-			Context("builtin functions", func() {
-				It("length of an empty string", func() {
+			Context("closures", func() {
+				It("closure captures its defining scope", func() {
 					text = `
-				 	len("");
+					let adder = fn(x) { fn(y) { x + y; }; };
+					let addTwo = adder(2);
+					addTwo(3);
 					`
-					expectedObject := object.NewInteger(0)
+					expectedObject := object.NewInteger(5)
 					expectedParseErrors := []error{}
 
 					// parse the program
@@ -1115,11 +1175,12 @@ var _ = Describe("Evaluator", func() {
 					Expect(obj).To(Equal(expectedObject))
 				})
 
-				It("length of a non-empty string", func() {
+				It("curried function", func() {
 					text = `
-						 len("hello world");
-						`
-					expectedObject := object.NewInteger(11)
+					let f = fn(a) { fn(b) { fn(c) { a * b + c; }; }; };
+					f(2)(3)(4);
+					`
+					expectedObject := object.NewInteger(10)
 					expectedParseErrors := []error{}
 
 					// parse the program
@@ -1132,12 +1193,89 @@ var _ = Describe("Evaluator", func() {
 					Expect(obj).To(Equal(expectedObject))
 				})
 
-				It("length on integer unsupported", func() {
+				It("names are resolved lexically, not from the caller", func() {
 					text = `
-						 len(1);
-						`
+					let x = 1;
+					let f = fn() { x; };
+					let g = fn(x) { f(); };
+					g(5);
+					`
+					expectedObject := object.NewInteger(1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bindings in a call do not leak into the caller", func() {
+					text = `
+					let x = 1;
+					let f = fn() { let x = 2; x; };
+					f() + x;
+					`
+					expectedObject := object.NewInteger(3)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("recursive function", func() {
+					text = `
+					let fib = fn(n) { if (n < 2) { return n; }; fib(n - 1) + fib(n - 2); };
+					fib(15);
+					`
+					expectedObject := object.NewInteger(610)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("recursion up to the maximum call depth", func() {
+					// f(n) makes n+1 nested calls
+					text = fmt.Sprintf(`
+					let f = fn(n) { if (n == 0) { return 0; }; return 1 + f(n - 1); };
+					f(%d);
+					`, object.MaxCallDepth-1)
+					expectedObject := object.NewInteger(object.MaxCallDepth - 1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+				It("unbounded recursion", func() {
+					text = `
+					let f = fn(x) { f(x); };
+					f(1);
+					`
 					expectedObject := object.NIL
 					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrStackOverflow
 
 					// parse the program
 					program, errs = p.ParseProgram(text)
@@ -1145,14 +1283,149 @@ var _ = Describe("Evaluator", func() {
 
 					// evaluate the AST tree
 					obj, err := e.Eval(program)
-					Expect(err).To(HaveOccurred())
+					Expect(err).To(MatchError(expectedEvaluateError))
 					Expect(obj).To(Equal(expectedObject))
 				})
 
-				It("length of an array", func() {
+				It("stack overflow can be caught", func() {
 					text = `
-						 len([1,2,true]);
-						`
+					let f = fn(x) { f(x); };
+					try { f(1); } catch (e) { [e["kind"], e["message"]]; };
+					`
+					expectedObject := object.NewArray(object.NewString("RecursionError"), object.NewString("stack overflow"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("loops", func() {
+				It("while loop", func() {
+					text = `
+					let i = 0;
+					let total = 0;
+					while (i < 5) { let total = total + i; let i = i + 1; };
+					total;
+					`
+					expectedObject := object.NewInteger(10)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("while loop evaluates to nil", func() {
+					text = `
+					while (false) { 1; };
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("for loop over an array", func() {
+					text = `
+					let total = 0;
+					for (x in [1, 2, 3]) { let total = total + x; };
+					total;
+					`
+					expectedObject := object.NewInteger(6)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("for loop over a string", func() {
+					text = `
+					let s = "";
+					for (ch in "abc") { let s = ch + s; };
+					s;
+					`
+					expectedObject := object.NewString("cba")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("for loop over a hash iterates over sorted keys", func() {
+					text = `
+					let keys = "";
+					for (k in {"b": 1, "a": 2}) { let keys = keys + k; };
+					keys;
+					`
+					expectedObject := object.NewString("ab")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("break and continue", func() {
+					text = `
+					let total = 0;
+					for (x in [1, 2, 3, 4, 5]) { if (x == 2) { continue; }; if (x == 4) { break; }; let total = total + x; };
+					total;
+					`
+					expectedObject := object.NewInteger(4)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("break only terminates the innermost loop", func() {
+					text = `
+					let total = 0;
+					for (x in [1, 2]) { for (y in [10, 20]) { break; }; let total = total + x; };
+					total;
+					`
 					expectedObject := object.NewInteger(3)
 					expectedParseErrors := []error{}
 
@@ -1165,6 +1438,3547 @@ var _ = Describe("Evaluator", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(obj).To(Equal(expectedObject))
 				})
+
+				It("return from a loop inside a function", func() {
+					text = `
+					let find = fn(arr) { for (x in arr) { if (x > 1) { return x; }; }; 0; };
+					find([1, 5, 7]);
+					`
+					expectedObject := object.NewInteger(5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("large loop does not exhaust the stack", func() {
+					text = `
+					let i = 0;
+					while (i < 100000) { let i = i + 1; };
+					i;
+					`
+					expectedObject := object.NewInteger(100000)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("iterating over a non iterable", func() {
+					text = `
+					for (x in 5) { x; };
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrNotIterable
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("error positions", func() {
+				It("errors point at the innermost node failing to evaluate", func() {
+					text = "let f = fn(x) {\n  x + foo;\n};\nf(1);"
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					_, err := e.Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrIdentifierNotFound))
+
+					var evalErr *evaluator.Error
+					Expect(errors.As(err, &evalErr)).To(BeTrue())
+					Expect(evalErr.Node.String()).To(Equal("foo"))
+					Expect(evalErr.Error()).To(Equal("2:7: identifier not found"))
+
+					pos, end := evalErr.Span()
+					Expect(pos).To(Equal(token.Position{Offset: 22, Line: 2, Column: 7}))
+					Expect(end).To(Equal(token.Position{Offset: 25, Line: 2, Column: 10}))
+				})
+			})
+
+			Context("error handling", func() {
+				It("catch a thrown value", func() {
+					text = `
+					let caught = 0;
+					try { throw "boom"; caught = "unreachable"; } catch (e) { caught = e; };
+					caught;
+					`
+					expectedObject := object.NewString("boom")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("catch a runtime error as an error object", func() {
+					text = `
+					let h = {"a": 1};
+					try { h["b"]; } catch (e) { [e["kind"], e["message"], e["stack"]]; };
+					`
+					expectedObject := object.NewArray(object.NewString("KeyError"), object.NewString("key not found"), object.NewArray(object.NewString("at <main> (3:12)")))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("catch an error thrown from a nested call", func() {
+					text = `
+					let f = fn() { throw error("bad thing"); };
+					let g = fn() { f(); };
+					try { g(); } catch (e) { e; };
+					`
+					expectedObject := object.NewError("Error", "bad thing", "at f (2:21)", "at g (3:21)", "at <main> (4:12)")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("try evaluates to the value of the try block when nothing is thrown", func() {
+					text = `
+					try { 1; } catch (e) { 2; };
+					`
+					expectedObject := object.NewInteger(1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("finally runs whether an error is thrown or not", func() {
+					text = `
+					let log = [];
+					try { log = log + 1; } catch (e) { 0; } finally { log = 1; };
+					let n = 0;
+					try { n = 1; } finally { n = n + 1; };
+					[log, n];
+					`
+					expectedObject := object.NewArray(object.NewInteger(1), object.NewInteger(2))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("finally runs when returning from the try block", func() {
+					text = `
+					let n = 0;
+					let f = fn() { try { return 1; } finally { n = 10; }; };
+					f() + n;
+					`
+					expectedObject := object.NewInteger(11)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("return in finally overrides the try block", func() {
+					text = `
+					let f = fn() { try { throw "boom"; } finally { return 2; }; };
+					f();
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("break and continue propagate through try", func() {
+					text = `
+					let sum = 0;
+					for (x in [1, 2, 3, 4]) { try { if (x == 2) { continue; }; if (x == 4) { break; }; sum += x; } finally { sum += 10; }; };
+					sum;
+					`
+					expectedObject := object.NewInteger(44)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("rethrow from a catch clause", func() {
+					text = `
+					let outer = 0;
+					try { try { throw 1; } catch (e) { throw e + 1; }; } catch (e) { outer = e; };
+					outer;
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("uncaught thrown values abort the evaluation", func() {
+					text = `
+					throw "boom";
+					`
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(obj).To(Equal(object.NIL))
+
+					var exception *evaluator.Exception
+					Expect(errors.As(err, &exception)).To(BeTrue())
+					Expect(exception.Value).To(Equal(object.NewString("boom")))
+					Expect(err.Error()).To(Equal("2:6: uncaught exception: boom"))
+				})
+
+				It("errors raised by a catch clause propagate", func() {
+					text = `
+					try { foo; } catch (e) { bar; };
+					`
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					_, err := e.Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrIdentifierNotFound))
+					Expect(err.Error()).To(HavePrefix("2:31:"))
+				})
+			})
+
+			Context("stack traces", func() {
+				It("runtime errors carry the frames of the calls in progress", func() {
+					text = "let inner = fn(x) { x[1]; };\nlet outer = fn() { fn() { inner([1]); }(); };\nouter();"
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					_, err := e.Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrIndexOutOfRange))
+
+					var evalErr *evaluator.Error
+					Expect(errors.As(err, &evalErr)).To(BeTrue())
+					Expect(evalErr.StackTrace()).To(Equal([]string{
+						"at inner (1:21)",
+						"at <anonymous> (2:27)",
+						"at outer (2:20)",
+						"at <main> (3:1)",
+					}))
+				})
+
+				It("frames of calls terminated by a caught error are discarded", func() {
+					text = "let fail = fn() { throw error(\"boom\"); };\ntry { fail(); } catch (e) { 0; };\nlet check = fn() { foo; };\ncheck();"
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					_, err := e.Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrIdentifierNotFound))
+
+					var evalErr *evaluator.Error
+					Expect(errors.As(err, &evalErr)).To(BeTrue())
+					Expect(evalErr.StackTrace()).To(Equal([]string{
+						"at check (3:20)",
+						"at <main> (4:1)",
+					}))
+				})
+
+				It("frames repeated by recursion are summarized", func() {
+					text = "let f = fn(x) { f(x); };\nf(1);"
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					_, err := e.Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrStackOverflow))
+
+					var evalErr *evaluator.Error
+					Expect(errors.As(err, &evalErr)).To(BeTrue())
+					Expect(evalErr.StackTrace()).To(Equal([]string{
+						"at f (1:17)",
+						"at f (1:17)",
+						"at f (1:17)",
+						fmt.Sprintf("[previous frame repeated %d more times]", object.MaxCallDepth-3),
+						"at <main> (2:1)",
+					}))
+				})
+
+				It("catch handlers can inspect the stack trace", func() {
+					text = "let f = fn() { {}[\"a\"]; };\ntry { f(); } catch (e) { e[\"stack\"]; };"
+					expectedObject := object.NewArray(object.NewString("at f (1:16)"), object.NewString("at <main> (2:7)"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("assignments", func() {
+				It("reassignment evaluates to the assigned value", func() {
+					text = `
+					let x = 1;
+					x = 5;
+					`
+					expectedObject := object.NewInteger(5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("compound assignment", func() {
+					text = `
+					let x = 10;
+					x += 5;
+					x -= 3;
+					x *= 2;
+					x /= 4;
+					x %= 4;
+					x;
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("chained assignment", func() {
+					text = `
+					let a = 0;
+					let b = 0;
+					a = b = 3;
+					a + b;
+					`
+					expectedObject := object.NewInteger(6)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("array element assignment", func() {
+					text = `
+					let arr = [1, 2, 3];
+					arr[0] = 10;
+					arr[2] *= 10;
+					arr;
+					`
+					expectedObject := object.NewArray(object.NewInteger(10), object.NewInteger(2), object.NewInteger(30))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("hash entry assignment", func() {
+					text = `
+					let h = {"a": 1};
+					h["a"] += 1;
+					h["b"] = 5;
+					h["a"] + h["b"];
+					`
+					expectedObject := object.NewInteger(7)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("assignment updates the binding captured by a closure", func() {
+					text = `
+					let counter = fn() { let count = 0; fn() { count += 1; }; };
+					let next = counter();
+					next();
+					next();
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("assignment inside a loop", func() {
+					text = `
+					let i = 0;
+					while (i < 10) { i += 1; };
+					i;
+					`
+					expectedObject := object.NewInteger(10)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("assignment to an undeclared identifier", func() {
+					text = `
+					x = 5;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrAssignToUndeclared
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(err).To(MatchError(HaveSuffix("assignment to undeclared identifier: x")))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("array element assignment out of range", func() {
+					text = `
+					let arr = [1];
+					arr[1] = 5;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrIndexOutOfRange
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("compound assignment to a missing hash entry", func() {
+					text = `
+					let h = {};
+					h["a"] += 1;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrKeyNotFound
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("floats", func() {
+				It("float literal", func() {
+					text = `
+					3.14;
+					`
+					expectedObject := object.NewFloat(3.14)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float literal with an exponent", func() {
+					text = `
+					2.5e-3;
+					`
+					expectedObject := object.NewFloat(0.0025)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("prefix minus on a float", func() {
+					text = `
+					-1.5;
+					`
+					expectedObject := object.NewFloat(-1.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float arithmetic", func() {
+					text = `
+					1.5 * 2.0 + 0.5;
+					`
+					expectedObject := object.NewFloat(3.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer division stays an integer", func() {
+					text = `
+					10 / 4;
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("mixing integers and floats promotes to float", func() {
+					text = `
+					10 / 4.0;
+					`
+					expectedObject := object.NewFloat(2.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("mixing integers and floats promotes to float", func() {
+					text = `
+					1 + 2.0;
+					`
+					expectedObject := object.NewFloat(3)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float comparison", func() {
+					text = `
+					1.5 < 2;
+					`
+					expectedObject := object.TRUE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integers and floats compare by value", func() {
+					text = `
+					1 == 1.0;
+					`
+					expectedObject := object.TRUE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("compound assignment promotes to float", func() {
+					text = `
+					let x = 1;
+					x += 0.5;
+					x;
+					`
+					expectedObject := object.NewFloat(1.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("average of an array", func() {
+					text = `
+					let xs = [1, 2, 3, 4];
+					let sum = 0;
+					for (x in xs) { sum += x; };
+					float(sum) / len(xs);
+					`
+					expectedObject := object.NewFloat(2.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("int truncates a float towards zero", func() {
+					text = `
+					[int(3.9), int(-3.9)];
+					`
+					expectedObject := object.NewArray(object.NewInteger(3), object.NewInteger(-3))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("int parses a string", func() {
+					text = `
+					int("42");
+					`
+					expectedObject := object.NewInteger(42)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float converts an integer", func() {
+					text = `
+					float(2);
+					`
+					expectedObject := object.NewFloat(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float parses a string", func() {
+					text = `
+					float("2.5");
+					`
+					expectedObject := object.NewFloat(2.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float keys in hashes", func() {
+					text = `
+					let h = {1.5: "a"};
+					h[1.5];
+					`
+					expectedObject := object.NewString("a")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("whole-number float keys find integer keys", func() {
+					text = `
+					let h = {1: "a", 2.5: "b"};
+					[h[1.0], h[2.5], has(h, 1.0)];
+					`
+					expectedObject := object.NewArray(object.NewString("a"), object.NewString("b"), object.TRUE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer and float keys with equal values are the same entry", func() {
+					text = `
+					len({1: "a", 1.0: "b"});
+					`
+					expectedObject := object.NewInteger(1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float division by zero", func() {
+					text = `
+					1.5 / 0;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float modulo by zero", func() {
+					text = `
+					1 % 0.0;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("int of an invalid string", func() {
+					text = `
+					int("x");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("int of a boolean", func() {
+					text = `
+					int(true);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("int of an infinite float", func() {
+					text = `
+					int(float("inf"));
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("big integers", func() {
+				It("addition overflowing int64 is promoted", func() {
+					text = `
+					9223372036854775807 + 1;
+					`
+					expectedObject := bigInteger("9223372036854775808")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("subtraction overflowing int64 is promoted", func() {
+					text = `
+					-9223372036854775807 - 2;
+					`
+					expectedObject := bigInteger("-9223372036854775809")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("multiplication overflowing int64 is promoted", func() {
+					text = `
+					4294967296 * 4294967296;
+					`
+					expectedObject := bigInteger("18446744073709551616")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("results that fit are demoted back to int64", func() {
+					text = `
+					9223372036854775807 + 1 - 1;
+					`
+					expectedObject := object.NewInteger(9223372036854775807)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("negating the smallest int64", func() {
+					text = `
+					-(-9223372036854775807 - 1);
+					`
+					expectedObject := bigInteger("9223372036854775808")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer literals overflowing int64", func() {
+					text = `
+					100000000000000000000 / 10;
+					`
+					expectedObject := bigInteger("10000000000000000000")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("factorial of 25", func() {
+					text = `
+					let fact = fn(n) { if (n < 2) { return 1; }; return n * fact(n - 1); };
+					fact(25);
+					`
+					expectedObject := bigInteger("15511210043330985984000000")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("comparisons between big and small integers", func() {
+					text = `
+					100000000000000000000 > 9223372036854775807;
+					`
+					expectedObject := object.TRUE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("equality of big integers", func() {
+					text = `
+					100000000000000000000 == 10000000000 * 10000000000;
+					`
+					expectedObject := object.TRUE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("big integers as hash keys", func() {
+					text = `
+					let h = {100000000000000000000: "big"};
+					h[10000000000 * 10000000000];
+					`
+					expectedObject := object.NewString("big")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("big integers mixed with floats", func() {
+					text = `
+					9223372036854775808 * 2.0;
+					`
+					expectedObject := object.NewFloat(18446744073709551616)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("indexing an array with a big integer", func() {
+					text = `
+					[1, 2, 3][9223372036854775808];
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrIndexOutOfRange
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("logical operators", func() {
+				It("and evaluates to the right operand when the left one is truthy", func() {
+					text = `
+					1 && 2;
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("and evaluates to the left operand when it is falsy", func() {
+					text = `
+					"" && 2;
+					`
+					expectedObject := object.NewString("")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("or evaluates to the left operand when it is truthy", func() {
+					text = `
+					"a" || 2;
+					`
+					expectedObject := object.NewString("a")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("or evaluates to the right operand when the left one is falsy", func() {
+					text = `
+					0 || [];
+					`
+					expectedObject := &object.Array{Elements: []object.Object{}}
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("and does not evaluate the right operand when the left one is falsy", func() {
+					text = `
+					let arr = [];
+					len(arr) > 0 && arr[0] > 1;
+					`
+					expectedObject := object.FALSE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("or does not evaluate the right operand when the left one is truthy", func() {
+					text = `
+					let count = 0;
+					true || (count += 1);
+					count;
+					`
+					expectedObject := object.NewInteger(0)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("and binds tighter than or", func() {
+					text = `
+					true || false && false;
+					`
+					expectedObject := object.TRUE
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bang agrees with the truthiness of conditions", func() {
+					text = `
+					[!0, !"", ![], !{}, !0.0, !"a", ![1]];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.TRUE, object.TRUE, object.TRUE, object.TRUE, object.FALSE, object.FALSE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("functions are truthy", func() {
+					text = `
+					let f = fn(x) { x; };
+					[!f, !len, !!f];
+					`
+					expectedObject := object.NewArray(object.FALSE, object.FALSE, object.TRUE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("or evaluates to a function on its left", func() {
+					text = `
+					let f = fn(x) { x; };
+					let g = f || fn(x) { 0; };
+					g(5);
+					`
+					expectedObject := object.NewInteger(5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("if takes the consequence for a function", func() {
+					text = `
+					let f = fn(x) { x; };
+					if (f) { 1; } else { 2; };
+					`
+					expectedObject := object.NewInteger(1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("nil literal", func() {
+					text = `
+					nil;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("nil guards the right operand", func() {
+					text = `
+					let check = fn(x) { x != nil && x[0] > 1; };
+					[check([2]), check(nil)];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.FALSE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("arithmetic and bitwise operators", func() {
+				It("integer modulo", func() {
+					text = `
+					-7 % 3;
+					`
+					expectedObject := object.NewInteger(-1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("float modulo", func() {
+					text = `
+					7.5 % 2;
+					`
+					expectedObject := object.NewFloat(1.5)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer power", func() {
+					text = `
+					2 ** 10;
+					`
+					expectedObject := object.NewInteger(1024)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer power overflowing int64", func() {
+					text = `
+					2 ** 64;
+					`
+					expectedObject := bigInteger("18446744073709551616")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("negative exponents result in a float", func() {
+					text = `
+					2 ** -2;
+					`
+					expectedObject := object.NewFloat(0.25)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("power is right associative and binds tighter than negation", func() {
+					text = `
+					-2 ** 3 ** 2;
+					`
+					expectedObject := object.NewInteger(-512)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bitwise and, or and xor", func() {
+					text = `
+					[6 & 3, 6 | 3, 6 ^ 3];
+					`
+					expectedObject := object.NewArray(object.NewInteger(2), object.NewInteger(7), object.NewInteger(5))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bitwise not", func() {
+					text = `
+					~5;
+					`
+					expectedObject := object.NewInteger(-6)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("shifts", func() {
+					text = `
+					[1 << 4, -16 >> 2];
+					`
+					expectedObject := object.NewArray(object.NewInteger(16), object.NewInteger(-4))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("left shift overflowing int64", func() {
+					text = `
+					1 << 64;
+					`
+					expectedObject := bigInteger("18446744073709551616")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bitwise operators on big integers", func() {
+					text = `
+					(1 << 64 | 1) & 3;
+					`
+					expectedObject := object.NewInteger(1)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("modulo assignment", func() {
+					text = `
+					let x = 10;
+					x %= 4;
+					`
+					expectedObject := object.NewInteger(2)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("division by zero can be caught", func() {
+					text = `
+					try { 1 / 0; } catch (e) { e["kind"]; };
+					`
+					expectedObject := object.NewString("ZeroDivisionError")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer division by zero", func() {
+					text = `
+					1 / 0;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("integer modulo by zero", func() {
+					text = `
+					5 % 0;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("big integer division by zero", func() {
+					text = `
+					(1 << 64) / 0;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("negative shift count", func() {
+					text = `
+					1 << -1;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrInvalidShiftCount
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("huge powers of 0, 1 and -1", func() {
+					text = `
+					[1 ** 10000000000, (-1) ** 10000000001, 0 ** 10000000000, 0 << 10000000000];
+					`
+					expectedObject := object.NewArray(object.NewInteger(1), object.NewInteger(-1), object.NewInteger(0), object.NewInteger(0))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("power too large", func() {
+					text = `
+					2 ** 10000000000;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrIntegerTooLarge
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("shift too large", func() {
+					text = `
+					1 << 10000000000;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrIntegerTooLarge
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("big integer shift too large", func() {
+					text = `
+					9223372036854775808 << 1048576;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrIntegerTooLarge
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bitwise operators on floats", func() {
+					text = `
+					1.5 & 1;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrUnexpectedOperatorType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("bitwise not on a float", func() {
+					text = `
+					~1.5;
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrUnexpectedObjectType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("unicode strings", func() {
+				It("len counts characters rather than bytes", func() {
+					text = `
+					len("héllo 😀");
+					`
+					expectedObject := object.NewInteger(7)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("escape sequences are decoded", func() {
+					text = `
+					len("a\tb\u{e9}");
+					`
+					expectedObject := object.NewInteger(4)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("raw strings keep backslashes", func() {
+					text = "`a\\nb`;"
+					expectedObject := object.NewString("a\\nb")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("for loops iterate over characters", func() {
+					text = `
+					let out = "";
+					for (c in "añ😀") { out = out + c + "|"; };
+					out;
+					`
+					expectedObject := object.NewString("a|ñ|😀|")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("string interpolation", func() {
+				It("interpolates expressions into strings", func() {
+					text = `
+					let name = "alice";
+					let items = [1, 2];
+					"hello ${name}, you have ${len(items)} items";
+					`
+					expectedObject := object.NewString("hello alice, you have 2 items")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("interpolated values are stringified with inspect", func() {
+					text = `
+					"${1 + 2} ${0.5} ${true} ${[1, "a"]}";
+					`
+					expectedObject := object.NewString("3 0.5 true [1, a]")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("interpolations can be nested", func() {
+					text = `
+					let x = "in";
+					"a ${"b ${x}"} c";
+					`
+					expectedObject := object.NewString("a b in c")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("escaped interpolations are kept as text", func() {
+					text = `
+					"\${x} costs $5";
+					`
+					expectedObject := object.NewString("${x} costs $5")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("errors in interpolated expressions", func() {
+					text = `
+					"${missing}";
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrIdentifierNotFound
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("collection builtins", func() {
+				It("first and last elements", func() {
+					text = `
+					[first([1, 2, 3]), last([1, 2, 3]), first([]), last([])];
+					`
+					expectedObject := object.NewArray(object.NewInteger(1), object.NewInteger(3), object.NIL, object.NIL)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("rest returns a copy without the first element", func() {
+					text = `
+					let a = [1, 2, 3];
+					let r = rest(a);
+					r[0] = 9;
+					[a, r, rest([])];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(3)), object.NewArray(object.NewInteger(9), object.NewInteger(3)), &object.Array{Elements: []object.Object{}})
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("push and pop change the array in place", func() {
+					text = `
+					let a = [1];
+					push(a, 2, 3);
+					let p = pop(a);
+					[a, p];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(1), object.NewInteger(2)), object.NewInteger(3))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("concat and reverse return new arrays", func() {
+					text = `
+					let a = [1, 2];
+					let c = concat(a, [3], []);
+					[a, c, reverse(c), reverse("héllo")];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(1), object.NewInteger(2)), object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(3)), object.NewArray(object.NewInteger(3), object.NewInteger(2), object.NewInteger(1)), object.NewString("olléh"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("slice with negative and out of range indexes", func() {
+					text = `
+					let a = [1, 2, 3, 4];
+					[slice(a, 1, 3), slice(a, -2), slice(a, 2, 100), slice(a, 3, 1)];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(2), object.NewInteger(3)), object.NewArray(object.NewInteger(3), object.NewInteger(4)), object.NewArray(object.NewInteger(3), object.NewInteger(4)), &object.Array{Elements: []object.Object{}})
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("contains and index_of compare values", func() {
+					text = `
+					let a = [1, "a", [2]];
+					[contains(a, 1.0), contains(a, [2]), contains(a, "b"), contains("monkey", "key"), index_of(a, "a"), index_of(a, 3)];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.TRUE, object.FALSE, object.TRUE, object.NewInteger(1), object.NewInteger(-1))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("keys, values and items in key order", func() {
+					text = `
+					let h = {"b": 2, "a": 1};
+					[keys(h), values(h), items(h), len(h)];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewString("a"), object.NewString("b")), object.NewArray(object.NewInteger(1), object.NewInteger(2)), object.NewArray(object.NewArray(object.NewString("a"), object.NewInteger(1)), object.NewArray(object.NewString("b"), object.NewInteger(2))), object.NewInteger(2))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("has, delete and merge", func() {
+					text = `
+					let h = {"a": 1, "b": 2};
+					let m = merge(h, {"b": 3, "c": 4});
+					let d = delete(h, "a");
+					[has(h, "a"), has(h, "b"), d, delete(h, "z"), keys(m), m["b"]];
+					`
+					expectedObject := object.NewArray(object.FALSE, object.TRUE, object.NewInteger(1), object.NIL, object.NewArray(object.NewString("a"), object.NewString("b"), object.NewString("c")), object.NewInteger(3))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("range with one, two and three arguments", func() {
+					text = `
+					[range(3), range(2, 5), range(10, 0, -4), range(0)];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(0), object.NewInteger(1), object.NewInteger(2)), object.NewArray(object.NewInteger(2), object.NewInteger(3), object.NewInteger(4)), object.NewArray(object.NewInteger(10), object.NewInteger(6), object.NewInteger(2)), &object.Array{Elements: []object.Object{}})
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("range rounds partial steps up", func() {
+					text = `
+					[range(0, 5, 2), range(5, 0), range(0, 1, -3), range(-9223372036854775807, 9223372036854775807, 9223372036854775807)];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(0), object.NewInteger(2), object.NewInteger(4)), &object.Array{Elements: []object.Object{}}, &object.Array{Elements: []object.Object{}}, object.NewArray(object.NewInteger(-9223372036854775807), object.NewInteger(0)))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("range stops before overflowing", func() {
+					text = `
+					range(9223372036854775806, 9223372036854775807, 5);
+					`
+					expectedObject := object.NewArray(object.NewInteger(9223372036854775806))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("type errors are caught as TypeError", func() {
+					text = `
+					try { push(1, 2); } catch (e) { [e["kind"], e["message"]]; };
+					`
+					expectedObject := object.NewArray(object.NewString("TypeError"), object.NewString("unsupported argument type: push expects an array as argument 1, got INTEGER"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("pop from an empty array is an IndexError", func() {
+					text = `
+					try { pop([]); } catch (e) { [e["kind"], e["message"]]; };
+					`
+					expectedObject := object.NewArray(object.NewString("IndexError"), object.NewString("empty array: pop from an empty array"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("wrong number of arguments", func() {
+					text = `
+					first([1], [2]);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrWrongNumberArguments
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("unsupported argument type", func() {
+					text = `
+					keys([1]);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("unhashable key", func() {
+					text = `
+					has({}, [1]);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("zero range step", func() {
+					text = `
+					range(1, 2, 0);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("range too long", func() {
+					text = `
+					range(100000000000);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("big slice index", func() {
+					text = `
+					slice([1], 99999999999999999999);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("higher-order builtins", func() {
+				It("map applies a closure to every element", func() {
+					text = `
+					let factor = 3;
+					map([1, 2, 3], fn(x) { x * factor; });
+					`
+					expectedObject := object.NewArray(object.NewInteger(3), object.NewInteger(6), object.NewInteger(9))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("map accepts builtin functions", func() {
+					text = `
+					map(["a", "bc"], len);
+					`
+					expectedObject := object.NewArray(object.NewInteger(1), object.NewInteger(2))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("filter keeps the truthy results", func() {
+					text = `
+					filter([1, 2, 3, 4, 0], fn(x) { x % 2 == 0; });
+					`
+					expectedObject := object.NewArray(object.NewInteger(2), object.NewInteger(4), object.NewInteger(0))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("reduce with and without an initial value", func() {
+					text = `
+					let add = fn(a, b) { a + b; };
+					[reduce([1, 2, 3], add), reduce([1, 2, 3], add, 10), reduce([], add, 0)];
+					`
+					expectedObject := object.NewArray(object.NewInteger(6), object.NewInteger(16), object.NewInteger(0))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("sort_by is stable and compares numbers by value", func() {
+					text = `
+					let people = [["bob", 30], ["alice", 25.5], ["carol", 30], ["dave", 2]];
+					map(sort_by(people, fn(p) { p[1]; }), fn(p) { p[0]; });
+					`
+					expectedObject := object.NewArray(object.NewString("dave"), object.NewString("alice"), object.NewString("bob"), object.NewString("carol"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("sort_by strings", func() {
+					text = `
+					sort_by(["pear", "fig", "apple"], fn(s) { s; });
+					`
+					expectedObject := object.NewArray(object.NewString("apple"), object.NewString("fig"), object.NewString("pear"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("any and all stop at the deciding element", func() {
+					text = `
+					let seen = [];
+					let check = fn(x) { push(seen, x); x > 1; };
+					[any([1, 2, 3], check), all([1, 2, 3], check), any([], check), all([], check), seen];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.FALSE, object.FALSE, object.TRUE, object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(1)))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("group_by collects the elements by key", func() {
+					text = `
+					let groups = group_by([1, 2, 3, 4, 5], fn(x) { if (x % 2 == 0) { "even"; } else { "odd"; }; });
+					[groups["even"], groups["odd"]];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewInteger(2), object.NewInteger(4)), object.NewArray(object.NewInteger(1), object.NewInteger(3), object.NewInteger(5)))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("each calls the function for its side effects", func() {
+					text = `
+					let total = 0;
+					let result = each([1, 2, 3], fn(x) { total += x; });
+					[result, total];
+					`
+					expectedObject := object.NewArray(object.NIL, object.NewInteger(6))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("exceptions thrown by callbacks can be caught", func() {
+					text = `
+					try { map([1, 2], fn(x) { if (x == 2) { throw "two"; }; x; }); } catch (e) { e; };
+					`
+					expectedObject := object.NewString("two")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("callbacks can call higher-order builtins", func() {
+					text = `
+					map([[1, 2], [3]], fn(xs) { reduce(xs, fn(a, b) { a + b; }); });
+					`
+					expectedObject := object.NewArray(object.NewInteger(3), object.NewInteger(3))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("the function argument must be callable", func() {
+					text = `
+					map([1], 2);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("sort_by keys must be comparable", func() {
+					text = `
+					sort_by([1, "a"], fn(x) { x; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("group_by keys must be hashable", func() {
+					text = `
+					group_by([1], fn(x) { [x]; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("reduce of an empty array without an initial value", func() {
+					text = `
+					reduce([], fn(a, b) { a; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrEmptyArray
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("callbacks are called with the element only", func() {
+					text = `
+					map([1], fn(a, b) { a; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrWrongNumberArguments
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("errors raised by callbacks propagate", func() {
+					text = `
+					map([1, 0], fn(x) { 1 / x; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("string builtins", func() {
+				It("split and join", func() {
+					text = `
+					let words = split("a,b,,c", ",");
+					join(words, "-");
+					`
+					expectedObject := object.NewString("a-b--c")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("split without a separator splits around white space", func() {
+					text = `
+					split("  one two\tthree ");
+					`
+					expectedObject := object.NewArray(object.NewString("one"), object.NewString("two"), object.NewString("three"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("join prints elements that are not strings", func() {
+					text = `
+					join([1, true, "x"], ", ");
+					`
+					expectedObject := object.NewString("1, true, x")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("trim removes white space or the given characters", func() {
+					text = `
+					trim("  hi \n") + trim("--hi--", "-");
+					`
+					expectedObject := object.NewString("hihi")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("upper and lower", func() {
+					text = `
+					upper("Héllo") + lower("WORLD");
+					`
+					expectedObject := object.NewString("HÉLLOworld")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("replace all or a number of occurrences", func() {
+					text = `
+					[replace("a.b.c", ".", "/"), replace("a.b.c", ".", "/", 1)];
+					`
+					expectedObject := object.NewArray(object.NewString("a/b/c"), object.NewString("a/b.c"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("starts_with and ends_with", func() {
+					text = `
+					[starts_with("monkey", "mon"), ends_with("monkey", "key"), starts_with("monkey", "key")];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.TRUE, object.FALSE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("index counts characters", func() {
+					text = `
+					[index("héllo", "llo"), index("hello", "z")];
+					`
+					expectedObject := object.NewArray(object.NewInteger(2), object.NewInteger(-1))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("substr takes a start and an optional length", func() {
+					text = `
+					[substr("héllo", 1, 3), substr("hello", -3), substr("hello", 3, 10)];
+					`
+					expectedObject := object.NewArray(object.NewString("éll"), object.NewString("llo"), object.NewString("lo"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("repeat", func() {
+					text = `
+					repeat("ab", 3) + repeat("x", 0);
+					`
+					expectedObject := object.NewString("ababab")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("pad_left and pad_right", func() {
+					text = `
+					[pad_left("7", 3, "0"), pad_right("ab", 4), pad_left("abc", 2), pad_left("1", 6, "ab")];
+					`
+					expectedObject := object.NewArray(object.NewString("007"), object.NewString("ab  "), object.NewString("abc"), object.NewString("ababa1"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("format uses printf-style verbs", func() {
+					text = `
+					format("%s is %d years and %.1f%% done, %v %v", "bob", 42, 99.25, true, [1, 2]);
+					`
+					expectedObject := object.NewString("bob is 42 years and 99.2% done, true [1, 2]")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("chars splits a string into characters", func() {
+					text = `
+					chars("hé!");
+					`
+					expectedObject := object.NewArray(object.NewString("h"), object.NewString("é"), object.NewString("!"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("ord and chr", func() {
+					text = `
+					[ord("A"), ord("é"), chr(97), chr(233)];
+					`
+					expectedObject := object.NewArray(object.NewInteger(65), object.NewInteger(233), object.NewString("a"), object.NewString("é"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("strings are indexed by character", func() {
+					text = `
+					let s = "héllo";
+					s[1] + s[4];
+					`
+					expectedObject := object.NewString("éo")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("slices of strings and arrays", func() {
+					text = `
+					let s = "héllo";
+					let a = [1, 2, 3, 4];
+					[s[1:3], s[:2], s[-3:], s[:], a[1:3], a[-1:], a[:-3]];
+					`
+					expectedObject := object.NewArray(object.NewString("él"), object.NewString("hé"), object.NewString("llo"), object.NewString("héllo"), object.NewArray(object.NewInteger(2), object.NewInteger(3)), object.NewArray(object.NewInteger(4)), object.NewArray(object.NewInteger(1)))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("out of range slices are clamped", func() {
+					text = `
+					[len("abc"[2:10]), len([1, 2][5:]), len("abc"[2:1])];
+					`
+					expectedObject := object.NewArray(object.NewInteger(1), object.NewInteger(0), object.NewInteger(0))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("slice builtin accepts strings", func() {
+					text = `
+					slice("héllo", 1, -1);
+					`
+					expectedObject := object.NewString("éll")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("chaining string builtins", func() {
+					text = `
+					let csv = "name, age\nbob, 42";
+					map(split(csv, "\n"), fn(line) { map(split(line, ","), trim); });
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewString("name"), object.NewString("age")), object.NewArray(object.NewString("bob"), object.NewString("42")))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("string index out of range", func() {
+					text = `
+					"abc"[3];
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrIndexOutOfRange
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("negative string index", func() {
+					text = `
+					"abc"[-1];
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrIndexOutOfRange
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("slice of an unsupported object", func() {
+					text = `
+					5[1:2];
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrUnexpectedObjectType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("slice index that is not an integer", func() {
+					text = `
+					"abc"["a":];
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrUnexpectedObjectType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("string builtin with a wrong argument type", func() {
+					text = `
+					upper(1);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("string builtin with a wrong number of arguments", func() {
+					text = `
+					split();
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrWrongNumberArguments
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("negative repeat count", func() {
+					text = `
+					repeat("a", -1);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("ord of more than one character", func() {
+					text = `
+					ord("ab");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("chr of an invalid code point", func() {
+					text = `
+					chr(55296);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("empty padding", func() {
+					text = `
+					pad_left("a", 3, "");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("padding result too long", func() {
+					text = `
+					pad_left("", 2000000000, "éééééééééé");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("regular expressions", func() {
+				It("re compiles a pattern with flags", func() {
+					text = `
+					let r = re("^err(or)?", "im");
+					"${r}";
+					`
+					expectedObject := object.NewString(`re("^err(or)?", "im")`)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("match finds a match anywhere", func() {
+					text = `
+					let r = re("[0-9]+");
+					[match("abc 42", r), match("abc", r), match("ERROR", re("error", "i"))];
+					`
+					expectedObject := object.NewArray(object.TRUE, object.FALSE, object.TRUE)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("find returns the leftmost match or nil", func() {
+					text = `
+					let r = re("[0-9]+");
+					[find("a1b22", r), find("ab", r)];
+					`
+					expectedObject := object.NewArray(object.NewString("1"), object.NIL)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("find_all returns all or the first n matches", func() {
+					text = `
+					let r = re("[0-9]+");
+					[find_all("a1b22c333", r), find_all("a1b22c333", r, 2), len(find_all("abc", r))];
+					`
+					expectedObject := object.NewArray(object.NewArray(object.NewString("1"), object.NewString("22"), object.NewString("333")), object.NewArray(object.NewString("1"), object.NewString("22")), object.NewInteger(0))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("captures returns the groups by index and by name", func() {
+					text = `
+					let r = re("(?P<level>[A-Z]+) (?P<code>[0-9]+)( retry)?");
+					let c = captures("at 10:00 WARN 503 disk", r);
+					[c[0], c[1], c["level"], c["code"], c[3], captures("none", r)];
+					`
+					expectedObject := object.NewArray(object.NewString("WARN 503"), object.NewString("WARN"), object.NewString("WARN"), object.NewString("503"), object.NIL, object.NIL)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("replace with a template", func() {
+					text = `
+					replace("2024-01-31", re("(?P<y>[0-9]+)-([0-9]+)-([0-9]+)"), "$3/$2/\${y}");
+					`
+					expectedObject := object.NewString("31/01/2024")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("replace with a callback", func() {
+					text = `
+					let n = 0;
+					replace("a1b22c333", re("[0-9]+"), fn(m) { n = n + 1; len(m); }) + format("%d", n);
+					`
+					expectedObject := object.NewString("a1b2c33")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("replace a number of matches", func() {
+					text = `
+					replace("a-b-c-d", re("-"), fn(m) { "+"; }, 2);
+					`
+					expectedObject := object.NewString("a+b+c-d")
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("split around a regex", func() {
+					text = `
+					split("a, b;c  ,d", re("[,;]\\s*"));
+					`
+					expectedObject := object.NewArray(object.NewString("a"), object.NewString("b"), object.NewString("c  "), object.NewString("d"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("parsing log lines", func() {
+					text = `
+					let line = re("^(?P<time>\\S+) \\[(?P<level>\\w+)\\] (?P<msg>.*)$");
+					let logs = ["10:00 [INFO] started", "10:01 [ERROR] disk full", "10:02 [ERROR] retry"];
+					map(filter(map(logs, fn(l) { captures(l, line); }), fn(c) { starts_with(c["level"], "ERR"); }), fn(c) { c["msg"]; });
+					`
+					expectedObject := object.NewArray(object.NewString("disk full"), object.NewString("retry"))
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("re with an invalid pattern", func() {
+					text = `
+					re("a(b");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("re with an unknown flag", func() {
+					text = `
+					re("a", "x");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrInvalidArgument
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("regex builtin without a regex", func() {
+					text = `
+					match("abc", "a");
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("replace with an unsupported replacement", func() {
+					text = `
+					replace("abc", re("b"), 1);
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := object.ErrUnsupportedArgumentType
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("errors raised by a replace callback", func() {
+					text = `
+					replace("abc", re("b"), fn(m) { 1 / 0; });
+					`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+					expectedEvaluateError := evaluator.ErrDivisionByZero
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(MatchError(expectedEvaluateError))
+					Expect(obj).To(Equal(expectedObject))
+				})
+			})
+
+			Context("builtin functions", func() {
+				It("length of an empty string", func() {
+					text = `
+				 	len("");
+					`
+					expectedObject := object.NewInteger(0)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("length of a non-empty string", func() {
+					text = `
+						 len("hello world");
+						`
+					expectedObject := object.NewInteger(11)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("length on integer unsupported", func() {
+					text = `
+						 len(1);
+						`
+					expectedObject := object.NIL
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).To(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("length of an array", func() {
+					text = `
+						 len([1,2,true]);
+						`
+					expectedObject := object.NewInteger(3)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(expectedObject))
+				})
+
+				It("builtins are looked up in the set of the evaluator", func() {
+					text = `
+						 answer() + len("ab");
+						`
+					builtins := object.NewBuiltins()
+					builtins["answer"] = func(ctx *object.CallContext, args ...object.Object) (object.Object, error) {
+						return object.NewInteger(40), nil
+					}
+					e = evaluator.NewWithBuiltins(object.NewEnvironment(), &bytes.Buffer{}, builtins)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(object.NewInteger(42)))
+					Expect(object.BuiltinFuncs).NotTo(HaveKey("answer"))
+
+					_, err = evaluator.New(object.NewEnvironment()).Eval(program)
+					Expect(err).To(MatchError(evaluator.ErrIdentifierNotFound))
+				})
+
+				It("print writes to the output of the evaluator", func() {
+					text = `
+						 let f = fn(x) { print("x is", x); };
+						 f(1);
+						 print([1, "a"]);
+						`
+					out := &bytes.Buffer{}
+					e = evaluator.NewWithOutput(object.NewEnvironment(), out)
+					expectedParseErrors := []error{}
+
+					// parse the program
+					program, errs = p.ParseProgram(text)
+					Expect(errs).To(Equal(expectedParseErrors))
+
+					// evaluate the AST tree
+					obj, err := e.Eval(program)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(obj).To(Equal(object.NIL))
+					Expect(out.String()).To(Equal("x is 1\n[1, a]\n"))
+				})
 			})
 		})
 	})
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/token"
+)
+
+// the names reported for code running outside of any function and for anonymous functions
+const (
+	mainFunction      = "<main>"
+	anonymousFunction = "<anonymous>"
+)
+
+// Frame is a single entry of a Monkey stack trace
+type Frame struct {
+	// the name the function is bound to by let, empty for anonymous functions
+	Function string
+	// the position being evaluated within the function
+	Pos token.Position
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = anonymousFunction
+	}
+
+	return fmt.Sprintf("at %s (%s)", name, f.Pos)
+}
+
+// callStack keeps track of the function calls in progress, it is shared by the evaluators of a call chain
+type callStack struct {
+	// the calls in progress, the innermost one last, each frame records the call site
+	frames []Frame
+}
+
+// push records a call to a function made at the given position,
+// it fails when the call would nest deeper than object.MaxCallDepth
+func (cs *callStack) push(function string, callSite token.Position) error {
+	if len(cs.frames) >= object.MaxCallDepth {
+		return ErrStackOverflow
+	}
+
+	cs.frames = append(cs.frames, Frame{Function: function, Pos: callSite})
+
+	return nil
+}
+
+// pop removes the innermost call
+func (cs *callStack) pop() {
+	cs.frames = cs.frames[:len(cs.frames)-1]
+}
+
+// trace builds the stack trace of a position in the innermost call, the innermost frame first
+func (cs *callStack) trace(pos token.Position) []Frame {
+	trace := make([]Frame, 0, len(cs.frames)+1)
+
+	for i := len(cs.frames) - 1; i >= 0; i-- {
+		// a frame is reported with the position currently evaluated in it,
+		// i.e. the error position for the innermost frame and the call site of its callee for the others
+		trace = append(trace, Frame{Function: cs.frames[i].Function, Pos: pos})
+		pos = cs.frames[i].Pos
+	}
+
+	return append(trace, Frame{Function: mainFunction, Pos: pos})
+}
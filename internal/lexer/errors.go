@@ -0,0 +1,12 @@
+package lexer
+
+import (
+	"errors"
+)
+
+var (
+	ErrIllegalCharacter    = errors.New("illegal character")
+	ErrUnterminatedString  = errors.New("unterminated string")
+	ErrUnterminatedComment = errors.New("unterminated comment")
+	ErrInvalidEscape       = errors.New("invalid escape sequence")
+)
@@ -1,6 +1,11 @@
 package lexer
 
 import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/aden-q/monkey/internal/bytesconv"
 	"github.com/aden-q/monkey/internal/token"
 )
@@ -12,11 +17,26 @@ type Lexer interface {
 	Read(text string) int
 	// NextToken reads the next token starting at the current offset and move the ptr forward
 	NextToken() token.Token
+	// Err returns why an ILLEGAL token could not be lexed, it returns nil for other tokens
+	Err(tok token.Token) error
 }
 
 type lexer struct {
 	buf      string
 	position uint32 // current position index in input
+
+	// line bookkeeping used to convert offsets to positions,
+	// the offsets up to scanned have been checked for newline characters
+	scanned   uint32
+	line      int
+	lineStart uint32
+
+	// the reasons of the ILLEGAL tokens read so far, keyed by their offsets
+	errs map[int]error
+
+	// the number of unclosed braces in each of the nested string interpolations being read,
+	// a closing brace without any unclosed brace ends the innermost interpolation
+	interpolations []int
 }
 
 func New() Lexer {
@@ -26,17 +46,52 @@ func New() Lexer {
 func (l *lexer) Read(text string) int {
 	l.buf = text
 	l.position = 0
+	l.scanned = 0
+	l.line = 1
+	l.lineStart = 0
+	l.errs = map[int]error{}
+	l.interpolations = nil
 
 	return len(text)
 }
 
+func (l *lexer) Err(tok token.Token) error {
+	if tok.Type != token.ILLEGAL {
+		return nil
+	}
+
+	if err, ok := l.errs[tok.Pos.Offset]; ok {
+		return err
+	}
+
+	return ErrIllegalCharacter
+}
+
 func (l *lexer) NextToken() token.Token {
-	l.skipWhiteSpaces()
+	comments, ok := l.skipTrivia()
+	if !ok {
+		// the unterminated block comment runs until the end of the input
+		last := comments[len(comments)-1]
+		l.errs[last.Pos.Offset] = ErrUnterminatedComment
+
+		return token.Token{
+			Type:     token.ILLEGAL,
+			Literal:  "/*",
+			Pos:      last.Pos,
+			End:      last.End,
+			Comments: comments[:len(comments)-1],
+		}
+	}
+
+	start := l.position
 
 	if !l.hasNext() {
 		return token.Token{
-			Type:    token.EOF,
-			Literal: "eof",
+			Type:     token.EOF,
+			Literal:  "eof",
+			Pos:      l.pos(start),
+			End:      l.pos(start),
+			Comments: comments,
 		}
 	}
 
@@ -45,8 +100,9 @@ func (l *lexer) NextToken() token.Token {
 
 	switch ch {
 	// operators with two characters
-	case '=', '!', '<', '>':
-		if l.peekNextNextChar() == '=' {
+	case '=', '!', '<', '>', '+', '-', '*', '/', '%', '&', '|':
+		// shift, power and logical operators are made of a doubled character
+		if l.peekNextNextChar() == '=' || (l.peekNextNextChar() == ch && strings.IndexByte("<>*&|", ch) >= 0) {
 			ch := bytesconv.BytesToString([]byte{l.readChar(), l.readChar()})
 			tok = token.New(token.LookupTokenType(ch), ch)
 		} else {
@@ -54,31 +110,88 @@ func (l *lexer) NextToken() token.Token {
 			tok = token.New(token.LookupTokenType(ch), ch)
 		}
 	// operators with a single character
-	case '+', '-', '*', '/':
-		fallthrough
+	case '^', '~':
+		ch := bytesconv.ByteToString(l.readChar())
+		tok = token.New(token.LookupTokenType(ch), ch)
+	// braces also delimit the expressions interpolated in strings
+	case '{', '}':
+		n := len(l.interpolations)
+
+		if ch == '}' && n > 0 && l.interpolations[n-1] == 0 {
+			// the end of the interpolated expression, the string continues
+			l.interpolations = l.interpolations[:n-1]
+			tok = l.readStringToken(start, token.STRING_MIDDLE, token.STRING_TAIL)
+
+			break
+		}
+
+		if n > 0 && ch == '{' {
+			l.interpolations[n-1]++
+		} else if n > 0 {
+			l.interpolations[n-1]--
+		}
+
+		ch := bytesconv.ByteToString(l.readChar())
+		tok = token.New(token.LookupTokenType(ch), ch)
 	// delimiters
-	case ',', ';', ':', '(', ')', '{', '}', '[', ']':
+	case ',', ';', ':', '(', ')', '[', ']':
 		ch := bytesconv.ByteToString(l.readChar())
 		tok = token.New(token.LookupTokenType(ch), ch)
 	case '"':
-		literal := l.readString()
-		tok = token.New(token.STRING, literal)
+		tok = l.readStringToken(start, token.STRING_HEAD, token.STRING)
+	case '`':
+		value, err := l.readRawString()
+		if err != nil {
+			// the literal of an illegal string is its source text
+			l.errs[int(start)] = err
+			tok = token.New(token.ILLEGAL, l.buf[start:l.position])
+		} else {
+			tok = token.New(token.STRING, value)
+		}
 	default:
+		r, size := utf8.DecodeRuneInString(l.buf[l.position:])
+
 		// read identifier
-		if isLetter(ch) {
+		if isLetter(r) {
 			literal := l.readWord()
 			tok = token.New(token.LookupTokenType(literal), literal)
 		} else if isDigit(ch) {
-			literal := l.readInt()
-			tok = token.New(token.LookupTokenType(literal), literal)
+			literal, isFloat := l.readNumber()
+			if isFloat {
+				tok = token.New(token.FLOAT, literal)
+			} else {
+				tok = token.New(token.INT, literal)
+			}
 		} else {
-			tok = token.New(token.ILLEGAL, string(ch))
+			tok = token.New(token.ILLEGAL, l.buf[l.position:l.position+uint32(size)])
+			l.position += uint32(size)
 		}
 	}
 
+	tok.Pos = l.pos(start)
+	tok.End = l.pos(l.position)
+	tok.Comments = comments
+
 	return tok
 }
 
+// pos converts a byte offset to a position in the source text,
+// offsets are expected to be converted in increasing order
+func (l *lexer) pos(offset uint32) token.Position {
+	for ; l.scanned < offset && l.scanned < uint32(len(l.buf)); l.scanned++ {
+		if l.buf[l.scanned] == '\n' {
+			l.line++
+			l.lineStart = l.scanned + 1
+		}
+	}
+
+	return token.Position{
+		Offset: int(offset),
+		Line:   l.line,
+		Column: int(offset-l.lineStart) + 1,
+	}
+}
+
 // hasNext checks whether there are characters remaining
 func (l *lexer) hasNext() bool {
 	return l.position < uint32(len(l.buf))
@@ -104,44 +217,154 @@ func (l *lexer) readChar() byte {
 	return l.buf[l.position-1]
 }
 
-// isLetter check whether a character is allow be to in an identifier
-func isLetter(ch byte) bool {
-	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch == '_'
+// isLetter check whether a character is allow be to in an identifier, letters of any script are allowed
+func isLetter(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
 }
 
 // read a word starting from the current position, and move the offset forward
 func (l *lexer) readWord() string {
 	startPos := l.position
 
-	for {
-		if !l.hasNext() {
+	for l.hasNext() {
+		r, size := utf8.DecodeRuneInString(l.buf[l.position:])
+		if !isLetter(r) {
 			break
 		}
 
-		ch := l.buf[l.position]
-		if !isLetter(ch) {
-			break
-		}
-
-		l.position++
+		l.position += uint32(size)
 	}
 
 	return l.buf[startPos:l.position]
 }
 
-// readString reads a string enclosed by ""
-func (l *lexer) readString() string {
+// escapes maps the characters following a backslash to the characters they stand for
+var escapes = map[byte]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'"':  '"',
+	'$':  '$',
+	'\\': '\\',
+}
+
+// readStringToken reads a string enclosed by "" or the rest of it after an interpolated expression,
+// the token is of the interpolated type when another interpolated expression follows, otherwise of the closed type
+func (l *lexer) readStringToken(start uint32, interpolated, closed token.TokenType) token.Token {
+	value, more, err := l.readString()
+
+	switch {
+	case err != nil:
+		// the literal of an illegal string is its source text
+		l.errs[int(start)] = err
+		return token.New(token.ILLEGAL, l.buf[start:l.position])
+	case more:
+		l.interpolations = append(l.interpolations, 0)
+		return token.New(interpolated, value)
+	default:
+		return token.New(closed, value)
+	}
+}
+
+// readString reads a string enclosed by "" and decodes its escape sequences, the string must be closed
+// on the line it starts, reading stops early at ${ and it reports whether an interpolated expression follows
+func (l *lexer) readString() (string, bool, error) {
+	// skip the opening " or the } closing the previous interpolated expression
 	l.position++
-	startPos := l.position
+
+	builder := strings.Builder{}
+
+	var err error
 
 	for {
-		ch := l.readChar()
-		if ch == 0 || ch == '"' {
-			break
+		if !l.hasNext() || l.buf[l.position] == '\n' {
+			return "", false, ErrUnterminatedString
+		}
+
+		switch ch := l.buf[l.position]; ch {
+		case '"':
+			l.position++
+			return builder.String(), false, err
+		case '$':
+			if l.peekNextNextChar() == '{' {
+				l.position += 2
+				return builder.String(), true, err
+			}
+
+			builder.WriteByte(ch)
+			l.position++
+		case '\\':
+			r, escapeErr := l.readEscape()
+			if escapeErr != nil && err == nil {
+				// keep reading so that the whole string is skipped
+				err = escapeErr
+			}
+
+			builder.WriteRune(r)
+		default:
+			builder.WriteByte(ch)
+			l.position++
 		}
 	}
+}
+
+// readEscape decodes the escape sequence starting with a backslash at the current offset,
+// a code point is written as \u{...} with 1 to 6 hex digits
+func (l *lexer) readEscape() (rune, error) {
+	l.position++
 
-	return l.buf[startPos : l.position-1]
+	ch := l.peekChar()
+	if r, ok := escapes[ch]; ok {
+		l.position++
+		return r, nil
+	}
+
+	if ch != 'u' || l.peekNextNextChar() != '{' {
+		return utf8.RuneError, ErrInvalidEscape
+	}
+
+	start := l.position + 2
+	l.position = start
+
+	for l.hasNext() && isHexDigit(l.buf[l.position]) {
+		l.position++
+	}
+
+	digits := l.buf[start:l.position]
+	if l.peekChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		return utf8.RuneError, ErrInvalidEscape
+	}
+
+	l.position++
+
+	value, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(value)) {
+		return utf8.RuneError, ErrInvalidEscape
+	}
+
+	return rune(value), nil
+}
+
+// readRawString reads a string enclosed by backticks, the content is kept as is and can span multiple lines
+func (l *lexer) readRawString() (string, error) {
+	l.position++
+
+	end := strings.IndexByte(l.buf[l.position:], '`')
+	if end < 0 {
+		l.position = uint32(len(l.buf))
+		return "", ErrUnterminatedString
+	}
+
+	value := l.buf[l.position : l.position+uint32(end)]
+	l.position += uint32(end) + 1
+
+	return value, nil
+}
+
+// isHexDigit check whether a character is a hexadecimal digit
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
 }
 
 // isLetter check whether a character is an digit
@@ -149,37 +372,122 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *lexer) readInt() string {
+// readNumber reads an integer or a floating-point literal, i.e. digits followed by
+// an optional fraction and an optional exponent, it reports whether the literal is a float
+func (l *lexer) readNumber() (string, bool) {
 	startPos := l.position
+	isFloat := false
 
-	for {
-		if !l.hasNext() {
-			break
+	l.skipDigits()
+
+	// a fraction requires digits after the dot
+	if l.peekChar() == '.' && isDigit(l.peekNextNextChar()) {
+		isFloat = true
+		l.position++
+		l.skipDigits()
+	}
+
+	// an exponent requires digits after the e, optionally signed
+	if ch := l.peekChar(); ch == 'e' || ch == 'E' {
+		offset := l.position + 1
+		if offset < uint32(len(l.buf)) && (l.buf[offset] == '+' || l.buf[offset] == '-') {
+			offset++
 		}
 
-		ch := l.buf[l.position]
-		if !isDigit(ch) {
-			break
+		if offset < uint32(len(l.buf)) && isDigit(l.buf[offset]) {
+			isFloat = true
+			l.position = offset
+			l.skipDigits()
 		}
+	}
 
+	return l.buf[startPos:l.position], isFloat
+}
+
+// skipDigits moves the offset past consecutive digits
+func (l *lexer) skipDigits() {
+	for l.hasNext() && isDigit(l.buf[l.position]) {
 		l.position++
 	}
+}
+
+// peekChar looks at the character at the current offset without moving forward
+func (l *lexer) peekChar() byte {
+	if !l.hasNext() {
+		return 0
+	}
 
-	return l.buf[startPos:l.position]
+	return l.buf[l.position]
 }
 
-// skipWhiteSpaces skips all white spaces starting at the current position, including newline characters
-func (l *lexer) skipWhiteSpaces() {
-	for {
-		if !l.hasNext() {
-			break
+// skipTrivia skips all white spaces and comments starting at the current position, including newline characters,
+// the comments are returned so that they can be attached to the next token, it reports false when the last
+// comment is a block comment that is never closed
+func (l *lexer) skipTrivia() ([]token.Comment, bool) {
+	var comments []token.Comment
+
+	for l.hasNext() {
+		start := l.position
+		rest := l.buf[l.position:]
+
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			l.position++
+			continue
+		case strings.HasPrefix(rest, "//") || rest[0] == '#':
+			l.skipLineComment()
+		case strings.HasPrefix(rest, "/*"):
+			if !l.skipBlockComment() {
+				comments = append(comments, l.comment(start))
+				return comments, false
+			}
+		default:
+			return comments, true
 		}
 
-		ch := l.buf[l.position]
-		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
-			l.position += 1
-		} else {
-			break
+		comments = append(comments, l.comment(start))
+	}
+
+	return comments, true
+}
+
+// skipLineComment moves the offset to the end of the line, the newline character is not part of the comment
+func (l *lexer) skipLineComment() {
+	for l.hasNext() && l.buf[l.position] != '\n' {
+		l.position++
+	}
+}
+
+// skipBlockComment moves the offset past a block comment, block comments can be nested,
+// it reports whether the comment is closed before the end of the input
+func (l *lexer) skipBlockComment() bool {
+	depth := 0
+
+	for l.hasNext() {
+		switch rest := l.buf[l.position:]; {
+		case strings.HasPrefix(rest, "/*"):
+			depth++
+			l.position += 2
+		case strings.HasPrefix(rest, "*/"):
+			depth--
+			l.position += 2
+
+			if depth == 0 {
+				return true
+			}
+		default:
+			l.position++
 		}
 	}
+
+	return false
+}
+
+// comment creates a comment from the start offset to the current offset
+func (l *lexer) comment(start uint32) token.Comment {
+	return token.Comment{
+		Text: l.buf[start:l.position],
+		Pos:  l.pos(start),
+		End:  l.pos(l.position),
+	}
 }
@@ -6,8 +6,12 @@ import (
 
 	"github.com/aden-q/monkey/internal/lexer"
 	"github.com/aden-q/monkey/internal/token"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
+// ignorePositions compares tokens by their type and literal only
+var ignorePositions = cmpopts.IgnoreTypes(token.Position{})
+
 var _ = Describe("Lexer", func() {
 	var (
 		text string
@@ -66,11 +70,358 @@ var _ = Describe("Lexer", func() {
 
 				Expect(l.Read(text)).To(Equal(len(text)))
 
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+		})
+
+		Context("keywords", func() {
+			It("can parse loop keywords", func() {
+				text = `while for in break continue`
+				expectedTokens := []token.Token{
+					token.New(token.WHILE, "while"),
+					token.New(token.FOR, "for"),
+					token.New(token.IN, "in"),
+					token.New(token.BREAK, "break"),
+					token.New(token.CONTINUE, "continue"),
+					token.New(token.EOF, "eof"),
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+
+			It("can parse the nil keyword", func() {
+				text = `nil nils`
+				expectedTokens := []token.Token{
+					token.New(token.NIL, "nil"),
+					token.New(token.IDENT, "nils"),
+					token.New(token.EOF, "eof"),
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+		})
+
+		Context("positions", func() {
+			It("tracks the line, column and offset of tokens", func() {
+				text = "let x = 5;\n  x + 10;"
+				expectedTokens := []token.Token{
+					{Type: token.LET, Literal: "let", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 3, Line: 1, Column: 4}},
+					{Type: token.IDENT, Literal: "x", Pos: token.Position{Offset: 4, Line: 1, Column: 5}, End: token.Position{Offset: 5, Line: 1, Column: 6}},
+					{Type: token.ASSIGN, Literal: "=", Pos: token.Position{Offset: 6, Line: 1, Column: 7}, End: token.Position{Offset: 7, Line: 1, Column: 8}},
+					{Type: token.INT, Literal: "5", Pos: token.Position{Offset: 8, Line: 1, Column: 9}, End: token.Position{Offset: 9, Line: 1, Column: 10}},
+					{Type: token.SEMICOLON, Literal: ";", Pos: token.Position{Offset: 9, Line: 1, Column: 10}, End: token.Position{Offset: 10, Line: 1, Column: 11}},
+					{Type: token.IDENT, Literal: "x", Pos: token.Position{Offset: 13, Line: 2, Column: 3}, End: token.Position{Offset: 14, Line: 2, Column: 4}},
+					{Type: token.PLUS, Literal: "+", Pos: token.Position{Offset: 15, Line: 2, Column: 5}, End: token.Position{Offset: 16, Line: 2, Column: 6}},
+					{Type: token.INT, Literal: "10", Pos: token.Position{Offset: 17, Line: 2, Column: 7}, End: token.Position{Offset: 19, Line: 2, Column: 9}},
+					{Type: token.SEMICOLON, Literal: ";", Pos: token.Position{Offset: 19, Line: 2, Column: 9}, End: token.Position{Offset: 20, Line: 2, Column: 10}},
+					{Type: token.EOF, Literal: "eof", Pos: token.Position{Offset: 20, Line: 2, Column: 10}, End: token.Position{Offset: 20, Line: 2, Column: 10}},
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
 				for _, expectedToken := range expectedTokens {
 					token := l.NextToken()
 					Expect(token).To(Equal(expectedToken))
 				}
 			})
+
+			It("moves past illegal characters", func() {
+				text = `@ x`
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.ILLEGAL)))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.IDENT)))
+			})
+		})
+
+		Context("error handling keywords", func() {
+			It("can parse error handling keywords", func() {
+				text = `try catch finally throw`
+				expectedTokens := []token.Token{
+					token.New(token.TRY, "try"),
+					token.New(token.CATCH, "catch"),
+					token.New(token.FINALLY, "finally"),
+					token.New(token.THROW, "throw"),
+					token.New(token.EOF, "eof"),
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+		})
+
+		Context("numbers", func() {
+			It("can parse integer and float literals", func() {
+				text = `5 3.14 1e3 2.5E-3 7e+2 1.e 4.x 99999999999999999999`
+				expectedTokens := []token.Token{
+					token.New(token.INT, "5"),
+					token.New(token.FLOAT, "3.14"),
+					token.New(token.FLOAT, "1e3"),
+					token.New(token.FLOAT, "2.5E-3"),
+					token.New(token.FLOAT, "7e+2"),
+					token.New(token.INT, "1"),
+					token.New(token.ILLEGAL, "."),
+					token.New(token.IDENT, "e"),
+					token.New(token.INT, "4"),
+					token.New(token.ILLEGAL, "."),
+					token.New(token.IDENT, "x"),
+					token.New(token.INT, "99999999999999999999"),
+					token.New(token.EOF, "eof"),
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+		})
+
+		Context("assignment operators", func() {
+			It("can parse compound assignment operators", func() {
+				text = `x = 1; x += 1; x -= 1; x *= 1; x /= 1; x %= 1; %`
+				expectedTypes := []token.TokenType{
+					token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON,
+					token.IDENT, token.PLUS_ASSIGN, token.INT, token.SEMICOLON,
+					token.IDENT, token.MINUS_ASSIGN, token.INT, token.SEMICOLON,
+					token.IDENT, token.ASTERISK_ASSIGN, token.INT, token.SEMICOLON,
+					token.IDENT, token.SLASH_ASSIGN, token.INT, token.SEMICOLON,
+					token.IDENT, token.PERCENT_ASSIGN, token.INT, token.SEMICOLON,
+					token.PERCENT,
+					token.EOF,
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedType := range expectedTypes {
+					token := l.NextToken()
+					Expect(token.Type).To(Equal(expectedType))
+				}
+			})
+		})
+
+		Context("logical operators", func() {
+			It("can parse logical operators", func() {
+				text = `a && b || c & d | e`
+				expectedTypes := []token.TokenType{
+					token.IDENT, token.AND, token.IDENT, token.OR, token.IDENT,
+					token.AMPERSAND, token.IDENT, token.PIPE, token.IDENT,
+					token.EOF,
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedType := range expectedTypes {
+					token := l.NextToken()
+					Expect(token.Type).To(Equal(expectedType))
+				}
+			})
+		})
+
+		Context("arithmetic and bitwise operators", func() {
+			It("can parse modulo, power, bitwise and shift operators", func() {
+				text = `a % b ** c & d | e ^ ~f << g >> h <= i * j`
+				expectedTypes := []token.TokenType{
+					token.IDENT, token.PERCENT, token.IDENT, token.POWER, token.IDENT,
+					token.AMPERSAND, token.IDENT, token.PIPE, token.IDENT, token.CARET,
+					token.TILDE, token.IDENT, token.SHL, token.IDENT, token.SHR, token.IDENT,
+					token.LTE, token.IDENT, token.ASTERISK, token.IDENT,
+					token.EOF,
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedType := range expectedTypes {
+					token := l.NextToken()
+					Expect(token.Type).To(Equal(expectedType))
+				}
+			})
+		})
+
+		Context("comments", func() {
+			It("attaches comments to the token that follows them", func() {
+				text = "// first\nx # second\n/* a /* nested */ block */ y; // last"
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				x := l.NextToken()
+				Expect(x.Literal).To(Equal("x"))
+				Expect(x.Comments).To(Equal([]token.Comment{
+					{Text: "// first", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 8, Line: 1, Column: 9}},
+				}))
+
+				y := l.NextToken()
+				Expect(y.Literal).To(Equal("y"))
+				Expect(y.Comments).To(HaveLen(2))
+				Expect(y.Comments[0].Text).To(Equal("# second"))
+				Expect(y.Comments[0].IsBlock()).To(BeFalse())
+				Expect(y.Comments[1].Text).To(Equal("/* a /* nested */ block */"))
+				Expect(y.Comments[1].IsBlock()).To(BeTrue())
+				Expect(y.Comments[1].Pos).To(Equal(token.Position{Offset: 20, Line: 3, Column: 1}))
+
+				semicolon := l.NextToken()
+				Expect(semicolon.Type).To(Equal(token.TokenType(token.SEMICOLON)))
+				Expect(semicolon.Comments).To(BeEmpty())
+
+				eof := l.NextToken()
+				Expect(eof.Type).To(Equal(token.TokenType(token.EOF)))
+				Expect(eof.Comments).To(HaveLen(1))
+				Expect(eof.Comments[0].Text).To(Equal("// last"))
+			})
+
+			It("reports an unterminated block comment as an illegal token", func() {
+				text = "x /* open /* nested */"
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.IDENT)))
+
+				illegal := l.NextToken()
+				Expect(illegal.Type).To(Equal(token.TokenType(token.ILLEGAL)))
+				Expect(illegal.Literal).To(Equal("/*"))
+				Expect(illegal.Pos.Offset).To(Equal(2))
+				Expect(illegal.End.Offset).To(Equal(len(text)))
+
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.EOF)))
+			})
+
+			It("slashes that do not start a comment are operators", func() {
+				text = `a / b /= c`
+				expectedTypes := []token.TokenType{
+					token.IDENT, token.SLASH, token.IDENT, token.SLASH_ASSIGN, token.IDENT,
+					token.EOF,
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedType := range expectedTypes {
+					token := l.NextToken()
+					Expect(token.Type).To(Equal(expectedType))
+				}
+			})
+		})
+
+		Context("strings", func() {
+			It("decodes escape sequences", func() {
+				text = `"a\tb\n\"c\" \\ \u{e9}\u{1F600}\0" ""`
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+				Expect(l.NextToken()).To(BeComparableTo(token.New(token.STRING, "a\tb\n\"c\" \\ \u00e9\U0001F600\x00"), ignorePositions))
+				Expect(l.NextToken()).To(BeComparableTo(token.New(token.STRING, ""), ignorePositions))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.EOF)))
+			})
+
+			It("keeps raw strings as is", func() {
+				text = "`a\\n\"b\"\nc` x"
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				raw := l.NextToken()
+				Expect(raw).To(BeComparableTo(token.New(token.STRING, "a\\n\"b\"\nc"), ignorePositions))
+				Expect(raw.End).To(Equal(token.Position{Offset: 10, Line: 2, Column: 3}))
+
+				x := l.NextToken()
+				Expect(x.Pos).To(Equal(token.Position{Offset: 11, Line: 2, Column: 4}))
+			})
+
+			It("reports unterminated strings", func() {
+				text = "\"abc\nx `def"
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				unterminated := l.NextToken()
+				Expect(unterminated).To(BeComparableTo(token.New(token.ILLEGAL, `"abc`), ignorePositions))
+				Expect(l.Err(unterminated)).To(Equal(lexer.ErrUnterminatedString))
+
+				x := l.NextToken()
+				Expect(x.Literal).To(Equal("x"))
+				Expect(l.Err(x)).To(BeNil())
+
+				unterminated = l.NextToken()
+				Expect(unterminated).To(BeComparableTo(token.New(token.ILLEGAL, "`def"), ignorePositions))
+				Expect(l.Err(unterminated)).To(Equal(lexer.ErrUnterminatedString))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.EOF)))
+			})
+
+			It("reports invalid escape sequences and skips the whole string", func() {
+				text = `"\q" "\u{110000}" "\u{}" x`
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, literal := range []string{`"\q"`, `"\u{110000}"`, `"\u{}"`} {
+					invalid := l.NextToken()
+					Expect(invalid).To(BeComparableTo(token.New(token.ILLEGAL, literal), ignorePositions))
+					Expect(l.Err(invalid)).To(Equal(lexer.ErrInvalidEscape))
+				}
+
+				Expect(l.NextToken().Literal).To(Equal("x"))
+			})
+		})
+
+		Context("string interpolation", func() {
+			It("splits strings around interpolated expressions", func() {
+				text = `"a ${b} c ${ {"d": "${e}"}["d"] } f" "\${g}"`
+				expectedTokens := []token.Token{
+					token.New(token.STRING_HEAD, "a "),
+					token.New(token.IDENT, "b"),
+					token.New(token.STRING_MIDDLE, " c "),
+					token.New(token.LBRACE, "{"),
+					token.New(token.STRING, "d"),
+					token.New(token.COLON, ":"),
+					token.New(token.STRING_HEAD, ""),
+					token.New(token.IDENT, "e"),
+					token.New(token.STRING_TAIL, ""),
+					token.New(token.RBRACE, "}"),
+					token.New(token.LBRACKET, "["),
+					token.New(token.STRING, "d"),
+					token.New(token.RBRACKET, "]"),
+					token.New(token.STRING_TAIL, " f"),
+					token.New(token.STRING, "${g}"),
+					token.New(token.EOF, "eof"),
+				}
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+
+				for _, expectedToken := range expectedTokens {
+					token := l.NextToken()
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
+				}
+			})
+		})
+
+		Context("unicode", func() {
+			It("identifiers can contain letters of any script", func() {
+				text = `let héllo = 名前; €`
+
+				Expect(l.Read(text)).To(Equal(len(text)))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.LET)))
+				Expect(l.NextToken()).To(BeComparableTo(token.New(token.IDENT, "héllo"), ignorePositions))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.ASSIGN)))
+				Expect(l.NextToken()).To(BeComparableTo(token.New(token.IDENT, "名前"), ignorePositions))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.SEMICOLON)))
+
+				illegal := l.NextToken()
+				Expect(illegal).To(BeComparableTo(token.New(token.ILLEGAL, "€"), ignorePositions))
+				Expect(l.Err(illegal)).To(Equal(lexer.ErrIllegalCharacter))
+				Expect(l.NextToken().Type).To(Equal(token.TokenType(token.EOF)))
+			})
 		})
 
 		Context("code snippet", func() {
@@ -85,7 +436,7 @@ var _ = Describe("Lexer", func() {
 					
 					let result = add(five, ten);
 
-					!-/*5;
+					!-/ *5;
 					5 < 10 > 5;
 
 					if (5 < 10) {
@@ -482,7 +833,7 @@ var _ = Describe("Lexer", func() {
 
 				for _, expectedToken := range expectedTokens {
 					token := l.NextToken()
-					Expect(token).To(Equal(expectedToken))
+					Expect(token).To(BeComparableTo(expectedToken, ignorePositions))
 				}
 			})
 		})
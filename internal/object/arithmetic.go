@@ -0,0 +1,253 @@
+package object
+
+import (
+	"math"
+	"math/big"
+)
+
+// MaxIntegerBits limits the size of the integers ** and << can produce, larger results would
+// take too long to compute or run out of memory
+const MaxIntegerBits = 1 << 20
+
+// IsNumber checks whether an object is an integer or a float
+func IsNumber(o Object) bool {
+	return o.Type() == INTEGER_OBJ || o.Type() == FLOAT_OBJ
+}
+
+// toFloat converts a number to a float
+func toFloat(o Object) float64 {
+	if i, ok := o.(*Integer); ok {
+		return i.Float64()
+	}
+
+	return o.(*Float).Value
+}
+
+// toBoolean converts a Go boolean to the boolean objects shared by all engines
+func toBoolean(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+
+	return FALSE
+}
+
+// NumberInfix applies an infix operator to two numbers, mixing an integer with a float promotes
+// the integer to a float. It is shared by the evaluator and the VM so that both agree on the results
+func NumberInfix(operator string, left, right Object) (Object, error) {
+	leftInt, leftOk := left.(*Integer)
+	rightInt, rightOk := right.(*Integer)
+
+	if leftOk && rightOk {
+		return IntegerInfix(operator, leftInt, rightInt)
+	}
+
+	return FloatInfix(operator, toFloat(left), toFloat(right))
+}
+
+// IntegerInfix applies an infix operator to two integers, the result is computed with arbitrary
+// precision when it overflows int64
+func IntegerInfix(operator string, left, right *Integer) (Object, error) {
+	switch operator {
+	case "/", "%":
+		if !right.IsBig() && right.Value == 0 {
+			return NIL, ErrDivisionByZero
+		}
+	case "**":
+		// a negative exponent results in a fraction
+		if right.BigInt().Sign() < 0 {
+			return FloatInfix(operator, left.Float64(), right.Float64())
+		}
+	case "<<", ">>":
+		if right.IsBig() || right.Value < 0 {
+			return NIL, ErrInvalidShiftCount
+		}
+	}
+
+	if left.IsBig() || right.IsBig() {
+		return bigIntegerInfix(operator, left.BigInt(), right.BigInt())
+	}
+
+	leftVal, rightVal := left.Value, right.Value
+
+	switch operator {
+	case "+":
+		if sum := leftVal + rightVal; (sum > leftVal) == (rightVal > 0) {
+			return NewInteger(sum), nil
+		}
+	case "-":
+		if diff := leftVal - rightVal; (diff < leftVal) == (rightVal > 0) {
+			return NewInteger(diff), nil
+		}
+	case "*":
+		if leftVal == 0 || rightVal == 0 {
+			return NewInteger(0), nil
+		}
+
+		product := leftVal * rightVal
+		if product/rightVal == leftVal && !(leftVal == -1 && rightVal == math.MinInt64) && !(rightVal == -1 && leftVal == math.MinInt64) {
+			return NewInteger(product), nil
+		}
+	case "/":
+		if leftVal != math.MinInt64 || rightVal != -1 {
+			return NewInteger(leftVal / rightVal), nil
+		}
+	case "%":
+		return NewInteger(leftVal % rightVal), nil
+	case "**", "<<":
+		// no fast path, these overflow easily
+	case "&":
+		return NewInteger(leftVal & rightVal), nil
+	case "|":
+		return NewInteger(leftVal | rightVal), nil
+	case "^":
+		return NewInteger(leftVal ^ rightVal), nil
+	case ">>":
+		return NewInteger(leftVal >> rightVal), nil
+	case "<":
+		return toBoolean(leftVal < rightVal), nil
+	case "<=":
+		return toBoolean(leftVal <= rightVal), nil
+	case ">":
+		return toBoolean(leftVal > rightVal), nil
+	case ">=":
+		return toBoolean(leftVal >= rightVal), nil
+	case "==":
+		return toBoolean(leftVal == rightVal), nil
+	case "!=":
+		return toBoolean(leftVal != rightVal), nil
+	default:
+		return NIL, ErrUnexpectedOperatorType
+	}
+
+	// the int64 result overflowed
+	return bigIntegerInfix(operator, left.BigInt(), right.BigInt())
+}
+
+// bigIntegerInfix applies an infix operator to two arbitrary-precision integers, results that fit
+// in int64 are demoted back
+func bigIntegerInfix(operator string, leftVal, rightVal *big.Int) (Object, error) {
+	switch operator {
+	case "+":
+		return NewBigInteger(new(big.Int).Add(leftVal, rightVal)), nil
+	case "-":
+		return NewBigInteger(new(big.Int).Sub(leftVal, rightVal)), nil
+	case "*":
+		return NewBigInteger(new(big.Int).Mul(leftVal, rightVal)), nil
+	case "/":
+		// truncated division, the same as int64
+		return NewBigInteger(new(big.Int).Quo(leftVal, rightVal)), nil
+	case "%":
+		return NewBigInteger(new(big.Int).Rem(leftVal, rightVal)), nil
+	case "**":
+		// powers of 0, 1 and -1 stay small whatever the exponent
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 {
+			if !rightVal.IsInt64() || rightVal.Int64() > MaxIntegerBits || int64(leftVal.BitLen()-1)*rightVal.Int64() > MaxIntegerBits {
+				return NIL, ErrIntegerTooLarge
+			}
+		}
+
+		return NewBigInteger(new(big.Int).Exp(leftVal, rightVal, nil)), nil
+	case "&":
+		return NewBigInteger(new(big.Int).And(leftVal, rightVal)), nil
+	case "|":
+		return NewBigInteger(new(big.Int).Or(leftVal, rightVal)), nil
+	case "^":
+		return NewBigInteger(new(big.Int).Xor(leftVal, rightVal)), nil
+	case "<<":
+		// the shift count is known to be a non-negative int64
+		if leftVal.Sign() != 0 && (rightVal.Int64() > MaxIntegerBits || int64(leftVal.BitLen())+rightVal.Int64() > MaxIntegerBits) {
+			return NIL, ErrIntegerTooLarge
+		}
+
+		return NewBigInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Uint64()))), nil
+	case ">>":
+		return NewBigInteger(new(big.Int).Rsh(leftVal, uint(rightVal.Uint64()))), nil
+	case "<":
+		return toBoolean(leftVal.Cmp(rightVal) < 0), nil
+	case "<=":
+		return toBoolean(leftVal.Cmp(rightVal) <= 0), nil
+	case ">":
+		return toBoolean(leftVal.Cmp(rightVal) > 0), nil
+	case ">=":
+		return toBoolean(leftVal.Cmp(rightVal) >= 0), nil
+	case "==":
+		return toBoolean(leftVal.Cmp(rightVal) == 0), nil
+	case "!=":
+		return toBoolean(leftVal.Cmp(rightVal) != 0), nil
+	default:
+		return NIL, ErrUnexpectedOperatorType
+	}
+}
+
+// FloatInfix applies an infix operator to two floats, division and modulo by zero are an error
+// like they are for integers rather than resulting in an infinity or NaN
+func FloatInfix(operator string, leftVal, rightVal float64) (Object, error) {
+	switch operator {
+	case "+":
+		return NewFloat(leftVal + rightVal), nil
+	case "-":
+		return NewFloat(leftVal - rightVal), nil
+	case "*":
+		return NewFloat(leftVal * rightVal), nil
+	case "/":
+		if rightVal == 0 {
+			return NIL, ErrDivisionByZero
+		}
+
+		return NewFloat(leftVal / rightVal), nil
+	case "%":
+		if rightVal == 0 {
+			return NIL, ErrDivisionByZero
+		}
+
+		return NewFloat(math.Mod(leftVal, rightVal)), nil
+	case "**":
+		return NewFloat(math.Pow(leftVal, rightVal)), nil
+	case "<":
+		return toBoolean(leftVal < rightVal), nil
+	case "<=":
+		return toBoolean(leftVal <= rightVal), nil
+	case ">":
+		return toBoolean(leftVal > rightVal), nil
+	case ">=":
+		return toBoolean(leftVal >= rightVal), nil
+	case "==":
+		return toBoolean(leftVal == rightVal), nil
+	case "!=":
+		return toBoolean(leftVal != rightVal), nil
+	default:
+		return NIL, ErrUnexpectedOperatorType
+	}
+}
+
+// Negate returns the negation of a number, ok is false when the object is not a number
+func Negate(o Object) (result Object, ok bool) {
+	switch o := o.(type) {
+	case *Integer:
+		// negating the smallest int64 overflows
+		if o.IsBig() || o.Value == math.MinInt64 {
+			return NewBigInteger(new(big.Int).Neg(o.BigInt())), true
+		}
+
+		return NewInteger(-o.Value), true
+	case *Float:
+		return NewFloat(-o.Value), true
+	default:
+		return NIL, false
+	}
+}
+
+// BitNot flips all the bits of an integer, i.e. ~x == -x - 1, ok is false when the object is not an integer
+func BitNot(o Object) (result Object, ok bool) {
+	integer, ok := o.(*Integer)
+	if !ok {
+		return NIL, false
+	}
+
+	if integer.IsBig() {
+		return NewBigInteger(new(big.Int).Not(integer.Big)), true
+	}
+
+	return NewInteger(^integer.Value), true
+}
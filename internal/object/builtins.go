@@ -2,33 +2,170 @@ package object
 
 import (
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// MaxCallDepth limits the number of nested function calls in both the evaluator and the VM,
+// deeper recursion fails with ErrStackOverflow instead of exhausting the memory of the host
+const MaxCallDepth = 10000
+
+// CallContext is what a builtin function gets from the interpreter calling it
+type CallContext struct {
+	// Out is where builtins such as print write their output
+	Out io.Writer
+	// Call calls a function object, e.g. a Monkey function passed to the builtin as an argument
+	Call func(fn Object, args ...Object) (Object, error)
+}
+
+// NewBuiltins returns a copy of the built-in functions, it can be changed without affecting other interpreters
+func NewBuiltins() map[string]BuiltinFunc {
+	return maps.Clone(BuiltinFuncs)
+}
+
 // all built-in functions
 var BuiltinFuncs = map[string]BuiltinFunc{
-	"len": func(args ...Object) (Object, error) {
+	"len": func(ctx *CallContext, args ...Object) (Object, error) {
 		if len(args) != 1 {
 			return NIL, ErrWrongNumberArguments
 		}
 
 		switch arg := args[0].(type) {
 		case *String:
-			return NewInteger(int64(len(arg.Value))), nil
+			// the length of a string is the number of characters, not bytes
+			return NewInteger(int64(utf8.RuneCountInString(arg.Value))), nil
 		case *Array:
 			return NewInteger(int64(len(arg.Elements))), nil
+		case *Hash:
+			return NewInteger(int64(len(arg.Items))), nil
 		default:
 			return NIL, ErrUnsupportedArgumentType
 		}
 	},
-	"print": func(args ...Object) (Object, error) {
+	"print": func(ctx *CallContext, args ...Object) (Object, error) {
 		strToPrint := []string{}
 		for _, arg := range args {
 			strToPrint = append(strToPrint, arg.Inspect())
 		}
 
-		fmt.Println(strings.Join(strToPrint, " "))
+		fmt.Fprintln(ctx.Out, strings.Join(strToPrint, " "))
 
 		return NIL, nil
 	},
+	"int": func(ctx *CallContext, args ...Object) (Object, error) {
+		if len(args) != 1 {
+			return NIL, ErrWrongNumberArguments
+		}
+
+		switch arg := args[0].(type) {
+		case *Integer:
+			return arg, nil
+		case *Float:
+			// the fraction is truncated towards zero
+			if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+				return NIL, ErrInvalidArgument
+			}
+
+			value, _ := big.NewFloat(arg.Value).Int(nil)
+			return NewBigInteger(value), nil
+		case *String:
+			value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+			if !ok {
+				return NIL, ErrInvalidArgument
+			}
+
+			return NewBigInteger(value), nil
+		default:
+			return NIL, ErrUnsupportedArgumentType
+		}
+	},
+	"float": func(ctx *CallContext, args ...Object) (Object, error) {
+		if len(args) != 1 {
+			return NIL, ErrWrongNumberArguments
+		}
+
+		switch arg := args[0].(type) {
+		case *Integer:
+			return NewFloat(arg.Float64()), nil
+		case *Float:
+			return arg, nil
+		case *String:
+			value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+			if err != nil {
+				return NIL, ErrInvalidArgument
+			}
+
+			return NewFloat(value), nil
+		default:
+			return NIL, ErrUnsupportedArgumentType
+		}
+	},
+	"error": func(ctx *CallContext, args ...Object) (Object, error) {
+		if len(args) != 1 {
+			return NIL, ErrWrongNumberArguments
+		}
+
+		msg, ok := args[0].(*String)
+		if !ok {
+			return NIL, ErrUnsupportedArgumentType
+		}
+
+		return NewError("Error", msg.Value), nil
+	},
+	// collections
+	"first":    builtinFirst,
+	"last":     builtinLast,
+	"rest":     builtinRest,
+	"push":     builtinPush,
+	"pop":      builtinPop,
+	"concat":   builtinConcat,
+	"reverse":  builtinReverse,
+	"slice":    builtinSlice,
+	"contains": builtinContains,
+	"index_of": builtinIndexOf,
+	"keys":     builtinKeys,
+	"values":   builtinValues,
+	"items":    builtinItems,
+	"has":      builtinHas,
+	"delete":   builtinDelete,
+	"merge":    builtinMerge,
+	"range":    builtinRange,
+	// higher-order functions
+	"map":      builtinMap,
+	"filter":   builtinFilter,
+	"reduce":   builtinReduce,
+	"sort_by":  builtinSortBy,
+	"any":      builtinAny,
+	"all":      builtinAll,
+	"group_by": builtinGroupBy,
+	"each":     builtinEach,
+	// strings
+	"split":       builtinSplit,
+	"join":        builtinJoin,
+	"trim":        builtinTrim,
+	"upper":       builtinUpper,
+	"lower":       builtinLower,
+	"replace":     builtinReplace,
+	"starts_with": builtinStartsWith,
+	"ends_with":   builtinEndsWith,
+	"index":       builtinIndex,
+	"substr":      builtinSubstr,
+	"repeat":      builtinRepeat,
+	"pad_left":    builtinPadLeft,
+	"pad_right":   builtinPadRight,
+	"format":      builtinFormat,
+	"chars":       builtinChars,
+	"ord":         builtinOrd,
+	"chr":         builtinChr,
+	// regular expressions
+	"re":       builtinRe,
+	"match":    builtinMatch,
+	"find":     builtinFind,
+	"find_all": builtinFindAll,
+	"captures": builtinCaptures,
 }
@@ -0,0 +1,552 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkArgCount checks that a builtin function is called with minArgs to maxArgs arguments,
+// maxArgs is math.MaxInt for functions taking any number of arguments
+func checkArgCount(name string, args []Object, minArgs, maxArgs int) error {
+	if len(args) >= minArgs && len(args) <= maxArgs {
+		return nil
+	}
+
+	var expected string
+
+	switch {
+	case minArgs == maxArgs:
+		expected = plural(minArgs, "argument")
+	case maxArgs == math.MaxInt:
+		expected = "at least " + plural(minArgs, "argument")
+	default:
+		expected = fmt.Sprintf("%d to %s", minArgs, plural(maxArgs, "argument"))
+	}
+
+	return fmt.Errorf("%w: %s takes %s, got %d", ErrWrongNumberArguments, name, expected, len(args))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// argTypeError reports an argument of a builtin function that has an unexpected type, i is 0-based
+func argTypeError(name string, i int, expected string, got Object) error {
+	return fmt.Errorf("%w: %s expects %s as argument %d, got %s", ErrUnsupportedArgumentType, name, expected, i+1, got.Type())
+}
+
+func arrayArg(name string, args []Object, i int) (*Array, error) {
+	array, ok := args[i].(*Array)
+	if !ok {
+		return nil, argTypeError(name, i, "an array", args[i])
+	}
+
+	return array, nil
+}
+
+func hashArg(name string, args []Object, i int) (*Hash, error) {
+	hash, ok := args[i].(*Hash)
+	if !ok {
+		return nil, argTypeError(name, i, "a hash", args[i])
+	}
+
+	return hash, nil
+}
+
+func hashKeyArg(name string, args []Object, i int) (HashKey, error) {
+	hashable, ok := args[i].(Hashable)
+	if !ok {
+		return HashKey{}, argTypeError(name, i, "a hashable key", args[i])
+	}
+
+	return hashable.HashKey(), nil
+}
+
+// intArg gets an integer argument that fits in int64
+func intArg(name string, args []Object, i int) (int64, error) {
+	integer, ok := args[i].(*Integer)
+	if !ok {
+		return 0, argTypeError(name, i, "an integer", args[i])
+	}
+
+	if integer.IsBig() {
+		return 0, fmt.Errorf("%w: argument %d of %s is out of range", ErrInvalidArgument, i+1, name)
+	}
+
+	return integer.Value, nil
+}
+
+// equal checks whether two objects are equal the same way ==, numbers are compared by value
+func equal(a, b Object) bool {
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Cmp(b) == 0
+		case *Float:
+			return a.Float64() == b.Value
+		}
+	case *Float:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == b.Float64()
+		case *Float:
+			return a.Value == b.Value
+		}
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// first returns the first element of an array, nil when it is empty
+func builtinFirst(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("first", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("first", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(array.Elements) == 0 {
+		return NIL, nil
+	}
+
+	return array.Elements[0], nil
+}
+
+// last returns the last element of an array, nil when it is empty
+func builtinLast(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("last", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("last", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(array.Elements) == 0 {
+		return NIL, nil
+	}
+
+	return array.Elements[len(array.Elements)-1], nil
+}
+
+// rest returns a new array with all the elements but the first one
+func builtinRest(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("rest", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("rest", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(array.Elements) == 0 {
+		return &Array{Elements: []Object{}}, nil
+	}
+
+	return NewArray(slices.Clone(array.Elements[1:])...), nil
+}
+
+// push appends values to an array in place and returns the array
+func builtinPush(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("push", args, 2, math.MaxInt); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("push", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	array.Elements = append(array.Elements, args[1:]...)
+
+	return array, nil
+}
+
+// pop removes the last element of an array in place and returns it
+func builtinPop(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("pop", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("pop", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(array.Elements) == 0 {
+		return NIL, fmt.Errorf("%w: pop from an empty array", ErrEmptyArray)
+	}
+
+	last := array.Elements[len(array.Elements)-1]
+	array.Elements = array.Elements[:len(array.Elements)-1]
+
+	return last, nil
+}
+
+// concat returns a new array with the elements of all the arrays
+func builtinConcat(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("concat", args, 1, math.MaxInt); err != nil {
+		return NIL, err
+	}
+
+	elements := []Object{}
+
+	for i := range args {
+		array, err := arrayArg("concat", args, i)
+		if err != nil {
+			return NIL, err
+		}
+
+		elements = append(elements, array.Elements...)
+	}
+
+	return NewArray(elements...), nil
+}
+
+// reverse returns a new array or string in reverse order
+func builtinReverse(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("reverse", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	switch arg := args[0].(type) {
+	case *Array:
+		elements := make([]Object, len(arg.Elements))
+		for i, element := range arg.Elements {
+			elements[len(elements)-1-i] = element
+		}
+
+		return NewArray(elements...), nil
+	case *String:
+		// strings are reversed by character, not by byte
+		runes := []rune(arg.Value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+
+		return NewString(string(runes)), nil
+	default:
+		return NIL, argTypeError("reverse", 0, "an array or a string", arg)
+	}
+}
+
+// slice returns a new array or string with the elements from start up to but not including end, negative
+// indexes count from the end and out of range indexes are clamped, end defaults to the length
+func builtinSlice(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("slice", args, 2, 3); err != nil {
+		return NIL, err
+	}
+
+	var length int64
+
+	switch arg := args[0].(type) {
+	case *Array:
+		length = int64(len(arg.Elements))
+	case *String:
+		length = int64(utf8.RuneCountInString(arg.Value))
+	default:
+		return NIL, argTypeError("slice", 0, "an array or a string", arg)
+	}
+
+	start, err := intArg("slice", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	end := length
+	if len(args) == 3 {
+		if end, err = intArg("slice", args, 2); err != nil {
+			return NIL, err
+		}
+	}
+
+	if array, ok := args[0].(*Array); ok {
+		return array.Slice(start, end), nil
+	}
+
+	return args[0].(*String).Slice(start, end), nil
+}
+
+// Slice returns a new array with the elements from low up to but not including high,
+// negative indexes count from the end of the array and out of range indexes are clamped
+func (a *Array) Slice(low, high int64) *Array {
+	length := int64(len(a.Elements))
+
+	low, high = clampIndex(low, length), clampIndex(high, length)
+	if low >= high {
+		return &Array{Elements: []Object{}}
+	}
+
+	return NewArray(slices.Clone(a.Elements[low:high])...)
+}
+
+// Iterate returns the values a for loop goes through: a snapshot of the elements of an array, so
+// that the loop is not affected by changes to the array, the characters of a string or the keys of
+// a hash. ok is false when the object is not iterable
+func Iterate(o Object) (elements []Object, ok bool) {
+	switch o := o.(type) {
+	case *Array:
+		return slices.Clone(o.Elements), true
+	case *String:
+		for _, ch := range o.Value {
+			elements = append(elements, NewString(string(ch)))
+		}
+
+		return elements, true
+	case *Hash:
+		return o.Keys(), true
+	default:
+		return nil, false
+	}
+}
+
+// clampIndex converts a possibly negative slice index to an index between 0 and length
+func clampIndex(index, length int64) int64 {
+	if index < 0 {
+		index += length
+	}
+
+	return max(0, min(index, length))
+}
+
+// contains checks whether an array contains a value, or whether a string contains a substring
+func builtinContains(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("contains", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	switch arg := args[0].(type) {
+	case *Array:
+		for _, element := range arg.Elements {
+			if equal(element, args[1]) {
+				return TRUE, nil
+			}
+		}
+
+		return FALSE, nil
+	case *String:
+		substr, ok := args[1].(*String)
+		if !ok {
+			return NIL, argTypeError("contains", 1, "a string", args[1])
+		}
+
+		return NewBoolean(strings.Contains(arg.Value, substr.Value)), nil
+	default:
+		return NIL, argTypeError("contains", 0, "an array or a string", arg)
+	}
+}
+
+// index_of returns the index of the first element of an array equal to a value, -1 when there is none
+func builtinIndexOf(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("index_of", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("index_of", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	for i, element := range array.Elements {
+		if equal(element, args[1]) {
+			return NewInteger(int64(i)), nil
+		}
+	}
+
+	return NewInteger(-1), nil
+}
+
+// keys returns the keys of a hash in sorted order
+func builtinKeys(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("keys", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	hash, err := hashArg("keys", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewArray(hash.Keys()...), nil
+}
+
+// values returns the values of a hash in the order of their keys
+func builtinValues(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("values", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	hash, err := hashArg("values", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	values := make([]Object, 0, len(hash.Items))
+	for _, key := range hash.Keys() {
+		values = append(values, hash.Items[key.(Hashable).HashKey()])
+	}
+
+	return NewArray(values...), nil
+}
+
+// items returns the [key, value] pairs of a hash in the order of their keys
+func builtinItems(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("items", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	hash, err := hashArg("items", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	items := make([]Object, 0, len(hash.Items))
+	for _, key := range hash.Keys() {
+		items = append(items, NewArray(key, hash.Items[key.(Hashable).HashKey()]))
+	}
+
+	return NewArray(items...), nil
+}
+
+// has checks whether a hash has a key
+func builtinHas(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("has", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	hash, err := hashArg("has", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	key, err := hashKeyArg("has", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	_, ok := hash.Items[key]
+
+	return NewBoolean(ok), nil
+}
+
+// delete removes a key from a hash in place and returns its value, nil when the key is missing
+func builtinDelete(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("delete", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	hash, err := hashArg("delete", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	key, err := hashKeyArg("delete", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	value, ok := hash.Items[key]
+	if !ok {
+		return NIL, nil
+	}
+
+	delete(hash.Items, key)
+
+	return value, nil
+}
+
+// merge returns a new hash with the items of all the hashes, later hashes override the keys of earlier ones
+func builtinMerge(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("merge", args, 1, math.MaxInt); err != nil {
+		return NIL, err
+	}
+
+	items := map[HashKey]Object{}
+
+	for i := range args {
+		hash, err := hashArg("merge", args, i)
+		if err != nil {
+			return NIL, err
+		}
+
+		for key, value := range hash.Items {
+			items[key] = value
+		}
+	}
+
+	return NewHash(items), nil
+}
+
+// maxRangeLength limits the number of elements range creates, larger arrays would run out of memory
+const maxRangeLength = 1 << 22
+
+// range returns the integers from start up to but not including end, counting by step,
+// it is called as range(end), range(start, end) or range(start, end, step)
+func builtinRange(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("range", args, 1, 3); err != nil {
+		return NIL, err
+	}
+
+	bounds := []int64{0, 0, 1}
+
+	for i := range args {
+		value, err := intArg("range", args, i)
+		if err != nil {
+			return NIL, err
+		}
+
+		bounds[i] = value
+	}
+
+	// a single argument is the end
+	if len(args) == 1 {
+		bounds[0], bounds[1] = 0, bounds[0]
+	}
+
+	start, end, step := bounds[0], bounds[1], bounds[2]
+	if step == 0 {
+		return NIL, fmt.Errorf("%w: range step must not be zero", ErrInvalidArgument)
+	}
+
+	// the number of elements is (end - start) / step rounded up,
+	// it is computed with big integers so that it cannot overflow
+	rounding := big.NewInt(step - 1)
+	if step < 0 {
+		rounding = big.NewInt(step + 1)
+	}
+
+	length := new(big.Int).Sub(big.NewInt(end), big.NewInt(start))
+	length.Add(length, rounding)
+	length.Quo(length, big.NewInt(step))
+
+	// the range is empty when end is before start in the direction of step
+	if length.Sign() < 0 {
+		length.SetInt64(0)
+	}
+
+	if length.Cmp(big.NewInt(maxRangeLength)) > 0 {
+		return NIL, fmt.Errorf("%w: range of %s elements is too long, at most %d are allowed", ErrInvalidArgument, length, maxRangeLength)
+	}
+
+	elements := make([]Object, length.Int64())
+	for i, value := 0, start; i < len(elements); i, value = i+1, value+step {
+		elements[i] = NewInteger(value)
+	}
+
+	return NewArray(elements...), nil
+}
@@ -4,14 +4,20 @@ package object
 var _ Environment = (*environment)(nil)
 
 type Environment interface {
+	// Keys returns the names visible from this scope, including the ones bound in outer scopes
 	Keys() []string
+	// Get looks up a name from this scope outwards
 	Get(name string) (Object, bool)
+	// Set binds a name in this scope, shadowing any binding in outer scopes
 	Set(name string, val Object)
-	copy() Environment
+	// Assign updates an existing binding in the scope it is bound in, it reports whether the name is bound
+	Assign(name string, val Object) bool
 }
 
 type environment struct {
 	store map[string]Object
+	// the enclosing scope, nil for the global scope
+	outer Environment
 }
 
 func NewEnvironment() Environment {
@@ -20,8 +26,13 @@ func NewEnvironment() Environment {
 	}
 }
 
-func NewClosureEnvironment(env Environment) Environment {
-	return env.copy()
+// NewEnclosedEnvironment creates a new scope chained to an outer one,
+// e.g. the scope of a function call enclosed by the scope the function was defined in
+func NewEnclosedEnvironment(outer Environment) Environment {
+	return &environment{
+		store: make(map[string]Object),
+		outer: outer,
+	}
 }
 
 func (e *environment) Keys() []string {
@@ -30,24 +41,45 @@ func (e *environment) Keys() []string {
 		keys = append(keys, k)
 	}
 
+	if e.outer == nil {
+		return keys
+	}
+
+	// names shadowed by this scope are only reported once
+	for _, k := range e.outer.Keys() {
+		if _, ok := e.store[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
 	return keys
 }
 
 func (e *environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
-	return obj, ok
+	if obj, ok := e.store[name]; ok {
+		return obj, true
+	}
+
+	if e.outer != nil {
+		return e.outer.Get(name)
+	}
+
+	return nil, false
 }
 
 func (e *environment) Set(name string, val Object) {
 	e.store[name] = val
 }
 
-// return a deep copy of the env
-func (e *environment) copy() Environment {
-	env := NewEnvironment()
-	for k, v := range e.store {
-		env.Set(k, v)
+func (e *environment) Assign(name string, val Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
 	}
 
-	return env
+	return false
 }
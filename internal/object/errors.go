@@ -7,4 +7,12 @@ import (
 var (
 	ErrWrongNumberArguments    = errors.New("wrong number of argument(s)")
 	ErrUnsupportedArgumentType = errors.New("unsupported argument type")
+	ErrInvalidArgument         = errors.New("invalid argument")
+	ErrEmptyArray              = errors.New("empty array")
+	ErrDivisionByZero          = errors.New("division by zero")
+	ErrInvalidShiftCount       = errors.New("invalid shift count")
+	ErrIntegerTooLarge         = errors.New("integer too large")
+	ErrUnexpectedOperatorType  = errors.New("unexpected operator type")
+	ErrNotIterable             = errors.New("object is not iterable")
+	ErrStackOverflow           = errors.New("stack overflow")
 )
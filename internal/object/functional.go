@@ -0,0 +1,311 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// funcArg gets an argument that can be called, i.e. a Monkey function or a builtin function
+func funcArg(name string, args []Object, i int) (Object, error) {
+	if args[i].Type() != FUNCTION_OBJ {
+		return nil, argTypeError(name, i, "a function", args[i])
+	}
+
+	return args[i], nil
+}
+
+// arrayAndFuncArgs gets the array and the function the higher-order builtins are called with
+func arrayAndFuncArgs(name string, args []Object) (*Array, Object, error) {
+	array, err := arrayArg(name, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fn, err := funcArg(name, args, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return array, fn, nil
+}
+
+// map returns a new array with the results of calling a function on every element
+func builtinMap(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("map", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("map", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	elements := make([]Object, 0, len(array.Elements))
+
+	for _, element := range array.Elements {
+		result, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		elements = append(elements, result)
+	}
+
+	return NewArray(elements...), nil
+}
+
+// filter returns a new array with the elements a function returns a truthy value for
+func builtinFilter(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("filter", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("filter", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	elements := []Object{}
+
+	for _, element := range array.Elements {
+		keep, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		if keep.IsTruthy() {
+			elements = append(elements, element)
+		}
+	}
+
+	return NewArray(elements...), nil
+}
+
+// reduce combines the elements from left to right by calling a function with the result so far
+// and the next element, the first element is the initial value when none is given
+func builtinReduce(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("reduce", args, 2, 3); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("reduce", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	elements := array.Elements
+
+	var result Object
+	if len(args) == 3 {
+		result = args[2]
+	} else {
+		if len(elements) == 0 {
+			return NIL, fmt.Errorf("%w: reduce of an empty array without an initial value", ErrEmptyArray)
+		}
+
+		result, elements = elements[0], elements[1:]
+	}
+
+	for _, element := range elements {
+		if result, err = ctx.Call(fn, result, element); err != nil {
+			return NIL, err
+		}
+	}
+
+	return result, nil
+}
+
+// sort_by returns a new array sorted by the keys a function returns for the elements, keys are either
+// all numbers or all strings, elements with equal keys keep their order
+func builtinSortBy(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("sort_by", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("sort_by", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	type keyed struct {
+		key     Object
+		element Object
+	}
+
+	pairs := make([]keyed, 0, len(array.Elements))
+
+	for _, element := range array.Elements {
+		key, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		if len(pairs) > 0 {
+			if _, err := compareKeys(pairs[0].key, key); err != nil {
+				return NIL, err
+			}
+		}
+
+		pairs = append(pairs, keyed{key, element})
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		// the keys are known to be comparable
+		c, _ := compareKeys(pairs[i].key, pairs[j].key)
+		return c < 0
+	})
+
+	elements := make([]Object, 0, len(pairs))
+	for _, pair := range pairs {
+		elements = append(elements, pair.element)
+	}
+
+	return NewArray(elements...), nil
+}
+
+// compareKeys compares two sort keys, numbers are compared by value and strings lexicographically
+func compareKeys(a, b Object) (int, error) {
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Cmp(b), nil
+		case *Float:
+			return compareFloats(a.Float64(), b.Value), nil
+		}
+	case *Float:
+		switch b := b.(type) {
+		case *Integer:
+			return compareFloats(a.Value, b.Float64()), nil
+		case *Float:
+			return compareFloats(a.Value, b.Value), nil
+		}
+	case *String:
+		if b, ok := b.(*String); ok {
+			return strings.Compare(a.Value, b.Value), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: sort_by cannot compare keys of types %s and %s", ErrUnsupportedArgumentType, a.Type(), b.Type())
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// any checks whether a function returns a truthy value for at least one element, it stops at the first one
+func builtinAny(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("any", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("any", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	for _, element := range array.Elements {
+		result, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		if result.IsTruthy() {
+			return TRUE, nil
+		}
+	}
+
+	return FALSE, nil
+}
+
+// all checks whether a function returns a truthy value for every element, it stops at the first falsy one
+func builtinAll(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("all", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("all", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	for _, element := range array.Elements {
+		result, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		if !result.IsTruthy() {
+			return FALSE, nil
+		}
+	}
+
+	return TRUE, nil
+}
+
+// group_by returns a hash from the keys a function returns for the elements to the arrays of elements
+// with that key, in their original order
+func builtinGroupBy(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("group_by", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("group_by", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	groups := map[HashKey]Object{}
+
+	for _, element := range array.Elements {
+		key, err := ctx.Call(fn, element)
+		if err != nil {
+			return NIL, err
+		}
+
+		hashable, ok := key.(Hashable)
+		if !ok {
+			return NIL, fmt.Errorf("%w: group_by expects hashable keys, got %s", ErrUnsupportedArgumentType, key.Type())
+		}
+
+		hashKey := hashable.HashKey()
+
+		group, ok := groups[hashKey].(*Array)
+		if !ok {
+			group = &Array{Elements: []Object{}}
+			groups[hashKey] = group
+		}
+
+		group.Elements = append(group.Elements, element)
+	}
+
+	return NewHash(groups), nil
+}
+
+// each calls a function on every element for its side effects
+func builtinEach(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("each", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	array, fn, err := arrayAndFuncArgs("each", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	for _, element := range array.Elements {
+		if _, err := ctx.Call(fn, element); err != nil {
+			return NIL, err
+		}
+	}
+
+	return NIL, nil
+}
@@ -1,16 +1,24 @@
 package object
 
 import (
+	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aden-q/monkey/internal/ast"
+	"github.com/aden-q/monkey/internal/code"
 )
 
 // interface compliance check
 var _ Object = (*Integer)(nil)
 var _ Hashable = (*Integer)(nil)
+var _ Object = (*Float)(nil)
+var _ Hashable = (*Float)(nil)
 var _ Object = (*Boolean)(nil)
 var _ Hashable = (*Boolean)(nil)
 var _ Object = (*String)(nil)
@@ -19,21 +27,30 @@ var _ Object = (*Array)(nil)
 var _ Object = (*Hash)(nil)
 var _ Object = (*Nil)(nil)
 var _ Object = (*ReturnValue)(nil)
+var _ Object = (*Break)(nil)
+var _ Object = (*Continue)(nil)
 var _ Object = (*Error)(nil)
+var _ Object = (*Regex)(nil)
 var _ Object = (*Func)(nil)
 var _ Object = (BuiltinFunc)(nil)
+var _ Object = (*CompiledFunc)(nil)
+var _ Object = (*Closure)(nil)
 
 type ObjectType string
 
 var (
 	INTEGER_OBJ      = ObjectType("INTEGER")
+	FLOAT_OBJ        = ObjectType("FLOAT")
 	BOOLEAN_OBJ      = ObjectType("BOOLEAN")
 	STRING_OBJ       = ObjectType("STRING")
 	ARRAY_OBJ        = ObjectType("ARRAY")
 	HASH_OBJ         = ObjectType("HASH")
 	NIL_OBJ          = ObjectType("NIL")
 	RETURN_VALUE_OBJ = ObjectType("RETURN_VALUE")
+	BREAK_OBJ        = ObjectType("BREAK")
+	CONTINUE_OBJ     = ObjectType("CONTINUE")
 	ERROR_OBJ        = ObjectType("ERROR")
+	REGEX_OBJ        = ObjectType("REGEX")
 	FUNCTION_OBJ     = ObjectType("FUNCTION")
 	BUILTINFUNC_OBJ  = ObjectType("BUILTINFUNC")
 )
@@ -45,6 +62,12 @@ var (
 	NIL   = NewNil()
 )
 
+// loop control signals
+var (
+	BREAK    = &Break{}
+	CONTINUE = &Continue{}
+)
+
 type Object interface {
 	Type() ObjectType
 	Inspect() string
@@ -61,9 +84,53 @@ type HashKey struct {
 	Value         uint64
 }
 
-// Integer
+// Object converts a hash key back to the object it was computed from
+func (k HashKey) Object() Object {
+	switch k.Type {
+	case INTEGER_OBJ:
+		// big integers hash their decimal literal, so the value is recovered from it
+		if value, ok := new(big.Int).SetString(k.ObjectLiteral, 10); ok {
+			return NewBigInteger(value)
+		}
+
+		return NewInteger(int64(k.Value))
+	case FLOAT_OBJ:
+		return NewFloat(math.Float64frombits(k.Value))
+	case BOOLEAN_OBJ:
+		if k.Value == 1 {
+			return TRUE
+		}
+
+		return FALSE
+	default:
+		return NewString(k.ObjectLiteral)
+	}
+}
+
+// less orders hash keys by type first, then by value
+func (k HashKey) less(other HashKey) bool {
+	if k.Type != other.Type {
+		return k.Type < other.Type
+	}
+
+	switch k.Type {
+	case INTEGER_OBJ:
+		return k.Object().(*Integer).Cmp(other.Object().(*Integer)) < 0
+	case FLOAT_OBJ:
+		return math.Float64frombits(k.Value) < math.Float64frombits(other.Value)
+	case BOOLEAN_OBJ:
+		return k.Value < other.Value
+	default:
+		return k.ObjectLiteral < other.ObjectLiteral
+	}
+}
+
+// Integer is an arbitrary-precision integer, values that fit in int64 are kept in Value
+// and larger ones are promoted to Big
 type Integer struct {
 	Value int64
+	// the value when it overflows int64, nil otherwise
+	Big *big.Int
 }
 
 func NewInteger(value int64) *Integer {
@@ -72,19 +139,85 @@ func NewInteger(value int64) *Integer {
 	}
 }
 
+// NewBigInteger creates an integer from a big value, it is demoted to int64 when it fits
+func NewBigInteger(value *big.Int) *Integer {
+	if value.IsInt64() {
+		return NewInteger(value.Int64())
+	}
+
+	return &Integer{
+		Big: value,
+	}
+}
+
+// IsBig reports whether the value overflows int64
+func (i *Integer) IsBig() bool {
+	return i.Big != nil
+}
+
+// BigInt returns the value as a big integer, the result must not be modified
+func (i *Integer) BigInt() *big.Int {
+	if i.Big != nil {
+		return i.Big
+	}
+
+	return big.NewInt(i.Value)
+}
+
+// Float64 returns the nearest floating-point value
+func (i *Integer) Float64() float64 {
+	if i.Big != nil {
+		value, _ := new(big.Float).SetInt(i.Big).Float64()
+		return value
+	}
+
+	return float64(i.Value)
+}
+
+// Cmp compares two integers and returns -1, 0 or +1
+func (i *Integer) Cmp(other *Integer) int {
+	if i.Big == nil && other.Big == nil {
+		switch {
+		case i.Value < other.Value:
+			return -1
+		case i.Value > other.Value:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return i.BigInt().Cmp(other.BigInt())
+}
+
 func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
 func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+
 	return strconv.FormatInt(i.Value, 10)
 }
 
 func (i *Integer) IsTruthy() bool {
-	return i.Value != 0
+	return i.Big != nil || i.Value != 0
 }
 
 func (i *Integer) HashKey() HashKey {
+	if i.Big != nil {
+		h := fnv.New64a()
+		h.Write([]byte(i.Big.String()))
+
+		return HashKey{
+			Type:          i.Type(),
+			ObjectLiteral: i.Inspect(),
+			Value:         h.Sum64(),
+		}
+	}
+
 	return HashKey{
 		Type:          i.Type(),
 		ObjectLiteral: i.Inspect(),
@@ -92,6 +225,50 @@ func (i *Integer) HashKey() HashKey {
 	}
 }
 
+// Float is a double precision floating-point number
+type Float struct {
+	Value float64
+}
+
+func NewFloat(value float64) *Float {
+	return &Float{
+		Value: value,
+	}
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
+// Inspect formats the shortest representation of the number, whole numbers keep a fraction
+// so that they can not be mistaken for integers, e.g. 2.0 instead of 2
+func (f *Float) Inspect() string {
+	literal := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(literal, ".eIN") {
+		return literal
+	}
+
+	return literal + ".0"
+}
+
+func (f *Float) IsTruthy() bool {
+	return f.Value != 0
+}
+
+// HashKey hashes whole numbers as integers so that equal keys such as 1 and 1.0 find the same entry
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		value, _ := big.NewFloat(f.Value).Int(nil)
+		return NewBigInteger(value).HashKey()
+	}
+
+	return HashKey{
+		Type:          f.Type(),
+		ObjectLiteral: f.Inspect(),
+		Value:         math.Float64bits(f.Value),
+	}
+}
+
 // the boolean object
 type Boolean struct {
 	Value bool
@@ -230,6 +407,25 @@ func (h *Hash) IsTruthy() bool {
 	return len(h.Items) > 0
 }
 
+// Keys returns the keys of the hash as objects, sorted so that iterating over a hash is deterministic
+func (h *Hash) Keys() []Object {
+	hashKeys := make([]HashKey, 0, len(h.Items))
+	for hashKey := range h.Items {
+		hashKeys = append(hashKeys, hashKey)
+	}
+
+	sort.Slice(hashKeys, func(i, j int) bool {
+		return hashKeys[i].less(hashKeys[j])
+	})
+
+	keys := make([]Object, 0, len(hashKeys))
+	for _, hashKey := range hashKeys {
+		keys = append(keys, hashKey.Object())
+	}
+
+	return keys
+}
+
 // Nil represents the absence of any value
 type Nil struct{}
 
@@ -272,15 +468,51 @@ func (rv *ReturnValue) IsTruthy() bool {
 	return rv.Value.IsTruthy()
 }
 
-// Error represents an error
-// Note: this is not necessary for the interpreter to work, we do error handling in Go's native way
+// Break signals that the innermost loop terminates
+type Break struct{}
+
+func (b *Break) Type() ObjectType {
+	return BREAK_OBJ
+}
+
+func (b *Break) Inspect() string {
+	return "break"
+}
+
+func (b *Break) IsTruthy() bool {
+	return false
+}
+
+// Continue signals that the innermost loop skips to its next iteration
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType {
+	return CONTINUE_OBJ
+}
+
+func (c *Continue) Inspect() string {
+	return "continue"
+}
+
+func (c *Continue) IsTruthy() bool {
+	return false
+}
+
+// Error represents a runtime error as a value, e.g. the error bound by a catch clause
 type Error struct {
+	// the kind of the error, e.g. KeyError for a missing hash key
+	Kind string
+	// a human readable description of the error
 	Message string
+	// the locations the error was raised at, the innermost one first
+	Stack []string
 }
 
-func NewError(msg string) *Error {
+func NewError(kind, msg string, stack ...string) *Error {
 	return &Error{
+		Kind:    kind,
 		Message: msg,
+		Stack:   stack,
 	}
 }
 
@@ -289,24 +521,126 @@ func (e *Error) Type() ObjectType {
 }
 
 func (e *Error) Inspect() string {
-	return "ERROR: " + e.Message
+	return e.Kind + ": " + e.Message
 }
 
-// FIXME: this behavior is undined, not sure an error is truthy or not
+// an error is a regular value once it is caught
 func (e *Error) IsTruthy() bool {
-	return false
+	return true
+}
+
+// Field looks up the attributes of an error exposed to programs, i.e. kind, message and stack
+func (e *Error) Field(name string) (Object, bool) {
+	switch name {
+	case "kind":
+		return NewString(e.Kind), true
+	case "message":
+		return NewString(e.Message), true
+	case "stack":
+		frames := make([]Object, 0, len(e.Stack))
+		for _, frame := range e.Stack {
+			frames = append(frames, NewString(frame))
+		}
+
+		return NewArray(frames...), true
+	default:
+		return nil, false
+	}
+}
+
+// Regex represents a compiled regular expression, it is compiled once and can be reused
+type Regex struct {
+	Pattern string
+	// the flags the pattern is compiled with, any of i, m, s and U
+	Flags  string
+	Regexp *regexp.Regexp
+}
+
+// NewRegex compiles a pattern in the syntax of Go's regexp package with the given flags
+func NewRegex(pattern, flags string) (*Regex, error) {
+	for _, flag := range flags {
+		if !strings.ContainsRune("imsU", flag) {
+			return nil, fmt.Errorf("%w: unknown regex flag %q", ErrInvalidArgument, flag)
+		}
+	}
+
+	expr := pattern
+	if flags != "" {
+		expr = "(?" + flags + ")" + pattern
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, err)
+	}
+
+	return &Regex{
+		Pattern: pattern,
+		Flags:   flags,
+		Regexp:  re,
+	}, nil
+}
+
+func (r *Regex) Type() ObjectType {
+	return REGEX_OBJ
+}
+
+// Inspect writes the call to re creating the regex, regexes have no literal syntax
+func (r *Regex) Inspect() string {
+	if r.Flags == "" {
+		return "re(" + quote(r.Pattern) + ")"
+	}
+
+	return "re(" + quote(r.Pattern) + ", " + quote(r.Flags) + ")"
+}
+
+func (r *Regex) IsTruthy() bool {
+	return true
+}
+
+// quote writes a string as a Monkey string literal, with the escape sequences the lexer decodes
+func quote(s string) string {
+	builder := strings.Builder{}
+	builder.WriteByte('"')
+
+	for i, r := range s {
+		switch {
+		case r == '"', r == '\\', r == '$' && strings.HasPrefix(s[i+1:], "{"):
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&builder, `\u{%x}`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	builder.WriteByte('"')
+
+	return builder.String()
 }
 
 // Func represents a function object
 type Func struct {
+	// the name the function literal is bound to by let, empty for anonymous functions
+	Name       string
 	Parameters []*ast.IdentifierExpression
 	Body       *ast.BlockStatement
+	// the environment the function is defined in, it is the outer scope of every call
+	Env Environment
 }
 
 func NewFunc(params []*ast.IdentifierExpression, body *ast.BlockStatement, env Environment) *Func {
 	return &Func{
 		Parameters: params,
 		Body:       body,
+		Env:        env,
 	}
 }
 
@@ -332,13 +666,12 @@ func (f *Func) Inspect() string {
 	return builder.String()
 }
 
-// FIXME: this behavior is undined, not sure whether a function is truthy or not
 func (f *Func) IsTruthy() bool {
-	return false
+	return true
 }
 
 // BuiltinFunc represents a builtin function object
-type BuiltinFunc func(args ...Object) (Object, error)
+type BuiltinFunc func(ctx *CallContext, args ...Object) (Object, error)
 
 func (b BuiltinFunc) Type() ObjectType {
 	return FUNCTION_OBJ
@@ -348,7 +681,58 @@ func (b BuiltinFunc) Inspect() string {
 	return "builtin function"
 }
 
-// FIXME: this behavior is undined, not sure whether a builtin function is truthy or not
 func (b BuiltinFunc) IsTruthy() bool {
-	return false
+	return true
+}
+
+// CompiledFunc represents a function compiled to bytecode, it is stored in the constant pool
+type CompiledFunc struct {
+	Instructions  code.Instructions
+	NumLocals     int
+	NumParameters int
+}
+
+func NewCompiledFunc(instructions code.Instructions, numLocals, numParameters int) *CompiledFunc {
+	return &CompiledFunc{
+		Instructions:  instructions,
+		NumLocals:     numLocals,
+		NumParameters: numParameters,
+	}
+}
+
+func (cf *CompiledFunc) Type() ObjectType {
+	return FUNCTION_OBJ
+}
+
+func (cf *CompiledFunc) Inspect() string {
+	return "compiled function"
+}
+
+func (cf *CompiledFunc) IsTruthy() bool {
+	return true
+}
+
+// Closure represents a compiled function together with the free variables it captured
+type Closure struct {
+	Fn   *CompiledFunc
+	Free []Object
+}
+
+func NewClosure(fn *CompiledFunc, free []Object) *Closure {
+	return &Closure{
+		Fn:   fn,
+		Free: free,
+	}
+}
+
+func (c *Closure) Type() ObjectType {
+	return FUNCTION_OBJ
+}
+
+func (c *Closure) Inspect() string {
+	return "closure"
+}
+
+func (c *Closure) IsTruthy() bool {
+	return true
 }
@@ -1,6 +1,9 @@
 package object_test
 
 import (
+	"math"
+	"math/big"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -40,6 +43,97 @@ var _ = Describe("Object", func() {
 		})
 	})
 
+	Describe("Big integer", func() {
+		It("promotes values overflowing int64 and demotes values that fit", func() {
+			value, _ := new(big.Int).SetString("9223372036854775808", 10)
+
+			obj := object.NewBigInteger(value)
+			Expect(obj.IsBig()).To(BeTrue())
+			Expect(obj.Inspect()).To(Equal("9223372036854775808"))
+			Expect(obj.IsTruthy()).To(BeTrue())
+
+			Expect(object.NewBigInteger(big.NewInt(42))).To(Equal(object.NewInteger(42)))
+		})
+
+		It("compares with small integers", func() {
+			value, _ := new(big.Int).SetString("-9223372036854775809", 10)
+
+			obj := object.NewBigInteger(value)
+			Expect(obj.Cmp(object.NewInteger(math.MinInt64))).To(Equal(-1))
+			Expect(object.NewInteger(1).Cmp(obj)).To(Equal(1))
+			Expect(object.NewInteger(1).Cmp(object.NewInteger(1))).To(Equal(0))
+		})
+
+		It("hash key converts back to the big integer", func() {
+			value, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+			obj := object.NewBigInteger(value)
+			Expect(obj.HashKey()).To(Equal(object.NewBigInteger(new(big.Int).Set(value)).HashKey()))
+			Expect(obj.HashKey().Object().Inspect()).To(Equal("123456789012345678901234567890"))
+			Expect(object.NewInteger(-7).HashKey().Object()).To(Equal(object.NewInteger(-7)))
+		})
+	})
+
+	Describe("NumberInfix", func() {
+		It("promotes int64 overflows and demotes results that fit", func() {
+			sum, err := object.NumberInfix("+", object.NewInteger(math.MaxInt64), object.NewInteger(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sum.Inspect()).To(Equal("9223372036854775808"))
+
+			diff, err := object.NumberInfix("-", sum, object.NewInteger(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(Equal(object.NewInteger(math.MaxInt64)))
+		})
+
+		It("promotes integers mixed with floats", func() {
+			product, err := object.NumberInfix("*", object.NewInteger(2), object.NewFloat(1.5))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(product).To(Equal(object.NewFloat(3)))
+		})
+
+		It("reports integer division by zero and invalid shift counts", func() {
+			_, err := object.NumberInfix("%", object.NewInteger(1), object.NewInteger(0))
+			Expect(err).To(MatchError(object.ErrDivisionByZero))
+
+			_, err = object.NumberInfix("<<", object.NewInteger(1), object.NewInteger(-1))
+			Expect(err).To(MatchError(object.ErrInvalidShiftCount))
+		})
+
+		It("reports float division by zero", func() {
+			_, err := object.NumberInfix("/", object.NewFloat(1), object.NewInteger(0))
+			Expect(err).To(MatchError(object.ErrDivisionByZero))
+
+			_, err = object.NumberInfix("%", object.NewFloat(1), object.NewFloat(0))
+			Expect(err).To(MatchError(object.ErrDivisionByZero))
+		})
+	})
+
+	Describe("Float", func() {
+		It("inspect keeps a fraction on whole numbers", func() {
+			Expect(object.NewFloat(2).Inspect()).To(Equal("2.0"))
+			Expect(object.NewFloat(-0.5).Inspect()).To(Equal("-0.5"))
+			Expect(object.NewFloat(0.30000000000000004).Inspect()).To(Equal("0.30000000000000004"))
+			Expect(object.NewFloat(1e21).Inspect()).To(Equal("1e+21"))
+			Expect(object.NewFloat(math.Inf(1)).Inspect()).To(Equal("+Inf"))
+		})
+
+		It("truthy float object", func() {
+			Expect(object.NewFloat(0.5).IsTruthy()).To(BeTrue())
+			Expect(object.NewFloat(0).IsTruthy()).To(BeFalse())
+		})
+
+		It("hash key converts back to the float", func() {
+			Expect(object.NewFloat(1.5).HashKey().Object()).To(Equal(object.NewFloat(1.5)))
+		})
+
+		It("whole numbers hash the same as integers", func() {
+			Expect(object.NewFloat(1).HashKey()).To(Equal(object.NewInteger(1).HashKey()))
+			Expect(object.NewFloat(-0.0).HashKey()).To(Equal(object.NewInteger(0).HashKey()))
+			Expect(object.NewFloat(1e19).HashKey()).To(Equal(object.NewBigInteger(big.NewInt(0).Exp(big.NewInt(10), big.NewInt(19), nil)).HashKey()))
+			Expect(object.NewFloat(math.Inf(1)).HashKey().Type).To(Equal(object.FLOAT_OBJ))
+		})
+	})
+
 	Describe("Boolean", func() {
 		It("truthy boolean object", func() {
 			var val bool = true
@@ -106,6 +200,27 @@ var _ = Describe("Object", func() {
 			Expect(obj.Type()).To(Equal(object.STRING_OBJ))
 			Expect(obj.IsTruthy()).To(Equal(true))
 		})
+
+		It("slices by character and clamps the indexes", func() {
+			obj := object.NewString("héllo")
+
+			Expect(obj.Slice(1, 3)).To(Equal(object.NewString("él")))
+			Expect(obj.Slice(-3, 100)).To(Equal(object.NewString("llo")))
+			Expect(obj.Slice(3, 1)).To(Equal(object.NewString("")))
+		})
+	})
+
+	Describe("Array", func() {
+		It("slices into a new array and clamps the indexes", func() {
+			obj := object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(3))
+
+			sliced := obj.Slice(-2, 100)
+			Expect(sliced).To(Equal(object.NewArray(object.NewInteger(2), object.NewInteger(3))))
+			Expect(obj.Slice(-100, 0)).To(Equal(&object.Array{Elements: []object.Object{}}))
+
+			sliced.Elements[0] = object.TRUE
+			Expect(obj.Elements[1]).To(Equal(object.NewInteger(2)))
+		})
 	})
 
 	Describe("Hash", func() {
@@ -139,4 +254,103 @@ var _ = Describe("Object", func() {
 			Expect(obj.IsTruthy()).To(Equal(true))
 		})
 	})
+
+	Describe("Environment", func() {
+		It("get a name bound in the same scope", func() {
+			env := object.NewEnvironment()
+			env.Set("a", object.NewInteger(1))
+
+			obj, ok := env.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(obj).To(Equal(object.NewInteger(1)))
+
+			_, ok = env.Get("b")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("get a name bound in an outer scope", func() {
+			outer := object.NewEnvironment()
+			outer.Set("a", object.NewInteger(1))
+			env := object.NewEnclosedEnvironment(outer)
+
+			obj, ok := env.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(obj).To(Equal(object.NewInteger(1)))
+		})
+
+		It("assign updates the scope a name is bound in", func() {
+			outer := object.NewEnvironment()
+			outer.Set("a", object.NewInteger(1))
+			env := object.NewEnclosedEnvironment(outer)
+
+			Expect(env.Assign("a", object.NewInteger(2))).To(BeTrue())
+			obj, _ := outer.Get("a")
+			Expect(obj).To(Equal(object.NewInteger(2)))
+
+			Expect(env.Assign("b", object.NewInteger(2))).To(BeFalse())
+			_, ok := env.Get("b")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("an enclosed scope shadows the outer scope", func() {
+			outer := object.NewEnvironment()
+			outer.Set("a", object.NewInteger(1))
+			outer.Set("b", object.NewInteger(2))
+			env := object.NewEnclosedEnvironment(outer)
+			env.Set("a", object.NewInteger(3))
+
+			obj, _ := env.Get("a")
+			Expect(obj).To(Equal(object.NewInteger(3)))
+			obj, _ = outer.Get("a")
+			Expect(obj).To(Equal(object.NewInteger(1)))
+			Expect(env.Keys()).To(ConsistOf("a", "b"))
+		})
+	})
+
+	Describe("Error", func() {
+		It("exposes its kind, message and stack", func() {
+			err := object.NewError("KeyError", "key not found", "at 1:2")
+			Expect(err.Inspect()).To(Equal("KeyError: key not found"))
+
+			kind, ok := err.Field("kind")
+			Expect(ok).To(BeTrue())
+			Expect(kind).To(Equal(object.NewString("KeyError")))
+
+			message, ok := err.Field("message")
+			Expect(ok).To(BeTrue())
+			Expect(message).To(Equal(object.NewString("key not found")))
+
+			stack, ok := err.Field("stack")
+			Expect(ok).To(BeTrue())
+			Expect(stack).To(Equal(object.NewArray(object.NewString("at 1:2"))))
+
+			_, ok = err.Field("cause")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Regex", func() {
+		It("compiles a pattern with flags", func() {
+			re, err := object.NewRegex("^warn", "im")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(re.Inspect()).To(Equal(`re("^warn", "im")`))
+			Expect(re.Type()).To(Equal(object.REGEX_OBJ))
+			Expect(re.IsTruthy()).To(BeTrue())
+			Expect(re.Regexp.FindAllString("ok\nWARN a\nwarn b", -1)).To(Equal([]string{"WARN", "warn"}))
+		})
+
+		It("inspects as the call to re creating it", func() {
+			re, err := object.NewRegex("\\d+ \"$\" ${x}\t\x01", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(re.Inspect()).To(Equal(`re("\\d+ \"$\" \${x}\t\u{1}")`))
+		})
+
+		It("rejects invalid patterns and unknown flags", func() {
+			_, err := object.NewRegex("a(", "")
+			Expect(err).To(MatchError(object.ErrInvalidArgument))
+
+			_, err = object.NewRegex("a", "g")
+			Expect(err).To(MatchError(object.ErrInvalidArgument))
+		})
+	})
 })
@@ -0,0 +1,181 @@
+package object
+
+import (
+	"fmt"
+)
+
+func regexArg(name string, args []Object, i int) (*Regex, error) {
+	re, ok := args[i].(*Regex)
+	if !ok {
+		return nil, argTypeError(name, i, "a regex", args[i])
+	}
+
+	return re, nil
+}
+
+// stringAndRegexArgs gets the string and the regex the regex builtins are called with
+func stringAndRegexArgs(name string, args []Object) (string, *Regex, error) {
+	str, err := stringArg(name, args, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	re, err := regexArg(name, args, 1)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return str, re, nil
+}
+
+// re compiles a pattern in the syntax of Go's regexp package, the optional flags are any of i (case
+// insensitive), m (multi-line), s (. matches \n) and U (ungreedy)
+func builtinRe(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("re", args, 1, 2); err != nil {
+		return NIL, err
+	}
+
+	pattern, err := stringArg("re", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	flags, err := optionalStringArg("re", args, 1, "")
+	if err != nil {
+		return NIL, err
+	}
+
+	re, err := NewRegex(pattern, flags)
+	if err != nil {
+		return NIL, err
+	}
+
+	return re, nil
+}
+
+// match checks whether a regex matches anywhere in a string
+func builtinMatch(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("match", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, re, err := stringAndRegexArgs("match", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewBoolean(re.Regexp.MatchString(str)), nil
+}
+
+// find returns the leftmost match of a regex in a string, nil when there is none
+func builtinFind(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("find", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, re, err := stringAndRegexArgs("find", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	loc := re.Regexp.FindStringIndex(str)
+	if loc == nil {
+		return NIL, nil
+	}
+
+	return NewString(str[loc[0]:loc[1]]), nil
+}
+
+// find_all returns the successive matches of a regex in a string, at most n of them when n is given
+func builtinFindAll(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("find_all", args, 2, 3); err != nil {
+		return NIL, err
+	}
+
+	str, re, err := stringAndRegexArgs("find_all", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	n := int64(-1)
+	if len(args) == 3 {
+		if n, err = intArg("find_all", args, 2); err != nil {
+			return NIL, err
+		}
+
+		if n < 0 {
+			return NIL, fmt.Errorf("%w: find_all count must not be negative", ErrInvalidArgument)
+		}
+	}
+
+	return stringsToArray(re.Regexp.FindAllString(str, int(n))), nil
+}
+
+// captures returns the groups of the leftmost match of a regex in a string as a hash, nil when there
+// is no match, the groups are keyed by their index with 0 for the whole match and named groups by
+// their name too, groups that did not take part in the match are nil
+func builtinCaptures(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("captures", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, re, err := stringAndRegexArgs("captures", args)
+	if err != nil {
+		return NIL, err
+	}
+
+	loc := re.Regexp.FindStringSubmatchIndex(str)
+	if loc == nil {
+		return NIL, nil
+	}
+
+	items := map[HashKey]Object{}
+
+	for i, name := range re.Regexp.SubexpNames() {
+		var group Object = NIL
+		if loc[2*i] >= 0 {
+			group = NewString(str[loc[2*i]:loc[2*i+1]])
+		}
+
+		items[NewInteger(int64(i)).HashKey()] = group
+		if name != "" {
+			items[NewString(name).HashKey()] = group
+		}
+	}
+
+	return NewHash(items), nil
+}
+
+// replaceRegex replaces the first n matches of a regex in a string, all of them when n is negative,
+// the replacement is either a string where $1 or ${name} stand for the groups of the match, or a
+// function called with every match that returns its replacement
+func replaceRegex(ctx *CallContext, str string, re *Regex, replacement Object, n int) (Object, error) {
+	template, isTemplate := replacement.(*String)
+	if !isTemplate && replacement.Type() != FUNCTION_OBJ {
+		return NIL, argTypeError("replace", 2, "a string or a function", replacement)
+	}
+
+	result := []byte{}
+	last := 0
+
+	for _, loc := range re.Regexp.FindAllStringSubmatchIndex(str, n) {
+		result = append(result, str[last:loc[0]]...)
+		last = loc[1]
+
+		if isTemplate {
+			result = re.Regexp.ExpandString(result, template.Value, str, loc)
+			continue
+		}
+
+		value, err := ctx.Call(replacement, NewString(str[loc[0]:loc[1]]))
+		if err != nil {
+			return NIL, err
+		}
+
+		result = append(result, value.Inspect()...)
+	}
+
+	result = append(result, str[last:]...)
+
+	return NewString(string(result)), nil
+}
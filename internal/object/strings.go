@@ -0,0 +1,469 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+func stringArg(name string, args []Object, i int) (string, error) {
+	str, ok := args[i].(*String)
+	if !ok {
+		return "", argTypeError(name, i, "a string", args[i])
+	}
+
+	return str.Value, nil
+}
+
+// optionalStringArg gets a string argument that defaults to value when it is not given
+func optionalStringArg(name string, args []Object, i int, value string) (string, error) {
+	if len(args) <= i {
+		return value, nil
+	}
+
+	return stringArg(name, args, i)
+}
+
+// stringsToArray converts Go strings to an array of string objects
+func stringsToArray(strs []string) *Array {
+	elements := make([]Object, 0, len(strs))
+	for _, str := range strs {
+		elements = append(elements, NewString(str))
+	}
+
+	return &Array{Elements: elements}
+}
+
+// Slice returns a new string with the characters from low up to but not including high,
+// negative indexes count from the end of the string and out of range indexes are clamped
+func (s *String) Slice(low, high int64) *String {
+	runes := []rune(s.Value)
+	length := int64(len(runes))
+
+	low, high = clampIndex(low, length), clampIndex(high, length)
+	if low >= high {
+		return NewString("")
+	}
+
+	return NewString(string(runes[low:high]))
+}
+
+// split splits a string around a separator, which is either a string or a regex, or around runs of
+// white space when there is no separator
+func builtinSplit(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("split", args, 1, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("split", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(args) == 1 {
+		return stringsToArray(strings.Fields(str)), nil
+	}
+
+	if re, ok := args[1].(*Regex); ok {
+		return stringsToArray(re.Regexp.Split(str, -1)), nil
+	}
+
+	sep, err := stringArg("split", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	return stringsToArray(strings.Split(str, sep)), nil
+}
+
+// join concatenates the elements of an array with a separator, elements that are not strings are
+// written the same way print does
+func builtinJoin(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("join", args, 1, 2); err != nil {
+		return NIL, err
+	}
+
+	array, err := arrayArg("join", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	sep, err := optionalStringArg("join", args, 1, "")
+	if err != nil {
+		return NIL, err
+	}
+
+	strs := make([]string, 0, len(array.Elements))
+	for _, element := range array.Elements {
+		strs = append(strs, element.Inspect())
+	}
+
+	return NewString(strings.Join(strs, sep)), nil
+}
+
+// trim removes leading and trailing white space, or the given characters, from a string
+func builtinTrim(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("trim", args, 1, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("trim", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if len(args) == 1 {
+		return NewString(strings.TrimSpace(str)), nil
+	}
+
+	cutset, err := stringArg("trim", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewString(strings.Trim(str, cutset)), nil
+}
+
+// upper returns a string with all letters in upper case
+func builtinUpper(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("upper", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("upper", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewString(strings.ToUpper(str)), nil
+}
+
+// lower returns a string with all letters in lower case
+func builtinLower(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("lower", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("lower", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewString(strings.ToLower(str)), nil
+}
+
+// replace replaces the occurrences of a substring or the matches of a regex, all of them unless a
+// count is given, see replaceRegex for the replacements allowed with a regex
+func builtinReplace(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("replace", args, 3, 4); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("replace", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	count := int64(-1)
+	if len(args) == 4 {
+		if count, err = intArg("replace", args, 3); err != nil {
+			return NIL, err
+		}
+
+		if count < 0 {
+			return NIL, fmt.Errorf("%w: replace count must not be negative", ErrInvalidArgument)
+		}
+	}
+
+	if re, ok := args[1].(*Regex); ok {
+		return replaceRegex(ctx, str, re, args[2], int(count))
+	}
+
+	old, err := stringArg("replace", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	replacement, err := stringArg("replace", args, 2)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewString(strings.Replace(str, old, replacement, int(count))), nil
+}
+
+// starts_with checks whether a string begins with a prefix
+func builtinStartsWith(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("starts_with", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("starts_with", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	prefix, err := stringArg("starts_with", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewBoolean(strings.HasPrefix(str, prefix)), nil
+}
+
+// ends_with checks whether a string ends with a suffix
+func builtinEndsWith(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("ends_with", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("ends_with", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	suffix, err := stringArg("ends_with", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	return NewBoolean(strings.HasSuffix(str, suffix)), nil
+}
+
+// index returns the character index of the first occurrence of a substring, -1 when there is none
+func builtinIndex(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("index", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("index", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	substr, err := stringArg("index", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	i := strings.Index(str, substr)
+	if i < 0 {
+		return NewInteger(-1), nil
+	}
+
+	return NewInteger(int64(utf8.RuneCountInString(str[:i]))), nil
+}
+
+// substr returns length characters of a string from start, or the rest of the string when there is
+// no length, a negative start counts from the end of the string
+func builtinSubstr(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("substr", args, 2, 3); err != nil {
+		return NIL, err
+	}
+
+	str, ok := args[0].(*String)
+	if !ok {
+		return NIL, argTypeError("substr", 0, "a string", args[0])
+	}
+
+	length := int64(utf8.RuneCountInString(str.Value))
+
+	start, err := intArg("substr", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	start = clampIndex(start, length)
+	end := length
+
+	if len(args) == 3 {
+		n, err := intArg("substr", args, 2)
+		if err != nil {
+			return NIL, err
+		}
+
+		if n < 0 {
+			return NIL, fmt.Errorf("%w: substr length must not be negative", ErrInvalidArgument)
+		}
+
+		end = start + min(n, length-start)
+	}
+
+	return str.Slice(start, end), nil
+}
+
+// repeat returns a string repeated n times
+func builtinRepeat(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("repeat", args, 2, 2); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("repeat", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	n, err := intArg("repeat", args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	if n < 0 {
+		return NIL, fmt.Errorf("%w: repeat count must not be negative", ErrInvalidArgument)
+	}
+
+	if n > 0 && int64(len(str)) > math.MaxInt32/n {
+		return NIL, fmt.Errorf("%w: repeat result is too long", ErrInvalidArgument)
+	}
+
+	return NewString(strings.Repeat(str, int(n))), nil
+}
+
+// pad_left pads the start of a string to a width in characters, with spaces unless a padding is given
+func builtinPadLeft(ctx *CallContext, args ...Object) (Object, error) {
+	return pad("pad_left", args, true)
+}
+
+// pad_right pads the end of a string to a width in characters, with spaces unless a padding is given
+func builtinPadRight(ctx *CallContext, args ...Object) (Object, error) {
+	return pad("pad_right", args, false)
+}
+
+func pad(name string, args []Object, left bool) (Object, error) {
+	if err := checkArgCount(name, args, 2, 3); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg(name, args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	width, err := intArg(name, args, 1)
+	if err != nil {
+		return NIL, err
+	}
+
+	padding, err := optionalStringArg(name, args, 2, " ")
+	if err != nil {
+		return NIL, err
+	}
+
+	if padding == "" {
+		return NIL, fmt.Errorf("%w: %s padding must not be empty", ErrInvalidArgument, name)
+	}
+
+	if width > math.MaxInt32 {
+		return NIL, fmt.Errorf("%w: %s width is too large", ErrInvalidArgument, name)
+	}
+
+	missing := int(width) - utf8.RuneCountInString(str)
+	if missing <= 0 {
+		return NewString(str), nil
+	}
+
+	// the padding is repeated and cut to fit when it has more than one character
+	paddingRunes := []rune(padding)
+	count, rest := missing/len(paddingRunes), missing%len(paddingRunes)
+
+	if int64(len(padding))*int64(count+1) > math.MaxInt32 {
+		return NIL, fmt.Errorf("%w: %s result is too long", ErrInvalidArgument, name)
+	}
+
+	fill := strings.Repeat(padding, count) + string(paddingRunes[:rest])
+
+	if left {
+		return NewString(fill + str), nil
+	}
+
+	return NewString(str + fill), nil
+}
+
+// format formats its arguments with the verbs of Go's fmt package, e.g. format("%s is %d", name, age),
+// integers, floats, strings and booleans are formatted as such and any other object as its printed form
+func builtinFormat(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("format", args, 1, math.MaxInt); err != nil {
+		return NIL, err
+	}
+
+	layout, err := stringArg("format", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	values := make([]any, 0, len(args)-1)
+
+	for _, arg := range args[1:] {
+		switch arg := arg.(type) {
+		case *Integer:
+			if arg.IsBig() {
+				values = append(values, arg.BigInt())
+			} else {
+				values = append(values, arg.Value)
+			}
+		case *Float:
+			values = append(values, arg.Value)
+		case *String:
+			values = append(values, arg.Value)
+		case *Boolean:
+			values = append(values, arg.Value)
+		default:
+			values = append(values, arg.Inspect())
+		}
+	}
+
+	return NewString(fmt.Sprintf(layout, values...)), nil
+}
+
+// chars returns the characters of a string as an array of strings
+func builtinChars(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("chars", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("chars", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	return stringsToArray(strings.Split(str, "")), nil
+}
+
+// ord returns the Unicode code point of a single character string
+func builtinOrd(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("ord", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	str, err := stringArg("ord", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if utf8.RuneCountInString(str) != 1 {
+		return NIL, fmt.Errorf("%w: ord expects a single character, got %q", ErrInvalidArgument, str)
+	}
+
+	r, _ := utf8.DecodeRuneInString(str)
+
+	return NewInteger(int64(r)), nil
+}
+
+// chr returns the single character string of a Unicode code point
+func builtinChr(ctx *CallContext, args ...Object) (Object, error) {
+	if err := checkArgCount("chr", args, 1, 1); err != nil {
+		return NIL, err
+	}
+
+	code, err := intArg("chr", args, 0)
+	if err != nil {
+		return NIL, err
+	}
+
+	if code < 0 || code > utf8.MaxRune || !utf8.ValidRune(rune(code)) {
+		return NIL, fmt.Errorf("%w: %d is not a valid code point", ErrInvalidArgument, code)
+	}
+
+	return NewString(string(rune(code))), nil
+}
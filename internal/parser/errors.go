@@ -2,10 +2,70 @@ package parser
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aden-q/monkey/internal/token"
 )
 
 var (
-	ErrUnexpectedTokenType   = errors.New("unexpected token type")
-	ErrPrefixParseFnNotFound = errors.New("prefix parse function not found")
-	ErrInfixParseFnNotFound  = errors.New("infix parse function not found")
+	ErrUnexpectedTokenType     = errors.New("unexpected token type")
+	ErrPrefixParseFnNotFound   = errors.New("prefix parse function not found")
+	ErrInfixParseFnNotFound    = errors.New("infix parse function not found")
+	ErrLoopControlOutsideLoop  = errors.New("break or continue outside of a loop")
+	ErrInvalidAssignmentTarget = errors.New("invalid assignment target")
 )
+
+// Error is a syntax error, it wraps one of the sentinel errors above
+// together with where it happened
+type Error struct {
+	// the sentinel error describing what went wrong
+	Err error
+	// the position the error points at
+	Pos token.Position
+	// the offending token
+	Token token.Token
+	// the token types that would have been accepted instead, empty when unknown
+	Expected []token.TokenType
+}
+
+func (e *Error) Error() string {
+	builder := strings.Builder{}
+
+	builder.WriteString(e.Pos.String() + ": ")
+	builder.WriteString(e.Err.Error())
+	builder.WriteString(", got " + describeToken(e.Token))
+
+	if len(e.Expected) != 0 {
+		expected := make([]string, 0, len(e.Expected))
+		for _, tokenType := range e.Expected {
+			expected = append(expected, string(tokenType))
+		}
+
+		builder.WriteString(", expected " + strings.Join(expected, " or "))
+	}
+
+	return builder.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the part of the source text the error points at
+func (e *Error) Span() (token.Position, token.Position) {
+	if e.Token.Pos == e.Pos {
+		return e.Token.Pos, e.Token.End
+	}
+
+	return e.Pos, e.Pos
+}
+
+// describeToken formats a token for error messages
+func describeToken(tok token.Token) string {
+	if tok.Type == token.EOF {
+		return "end of input"
+	}
+
+	return fmt.Sprintf("%q", tok.Literal)
+}
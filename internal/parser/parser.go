@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"errors"
+	"math/big"
 	"strconv"
 
 	"github.com/aden-q/monkey/internal/token"
@@ -34,6 +36,10 @@ type parser struct {
 	// parse functions for expressions
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	// the number of loops enclosing the current token within the current function body,
+	// break and continue statements are only allowed when it is positive
+	loopDepth int
 }
 
 func New(l lexer.Lexer) Parser {
@@ -48,11 +54,15 @@ func New(l lexer.Lexer) Parser {
 	p.registerPrefixParseFn(token.IDENT, p.parseIdentifier)
 	// handler for integer expression
 	p.registerPrefixParseFn(token.INT, p.parseInteger)
+	// handler for float expression
+	p.registerPrefixParseFn(token.FLOAT, p.parseFloat)
 	// handler for boolean expression
 	p.registerPrefixParseFn(token.TRUE, p.parseBoolean)
 	p.registerPrefixParseFn(token.FALSE, p.parseBoolean)
+	p.registerPrefixParseFn(token.NIL, p.parseNil)
 	// handler for string expression
 	p.registerPrefixParseFn(token.STRING, p.parseString)
+	p.registerPrefixParseFn(token.STRING_HEAD, p.parseInterpolatedString)
 	// handler for array expression
 	p.registerPrefixParseFn(token.LBRACKET, p.parseArrayExpression)
 	// handler for hash expression
@@ -67,18 +77,35 @@ func New(l lexer.Lexer) Parser {
 	p.registerPrefixParseFn(token.BANG, p.parsePrefixExpression)
 	// handler for -something expression
 	p.registerPrefixParseFn(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefixParseFn(token.TILDE, p.parsePrefixExpression)
 
 	// register infix parse functions
 	p.registerInfixParseFn(token.PLUS, p.parseInfixExpression)
 	p.registerInfixParseFn(token.MINUS, p.parseInfixExpression)
 	p.registerInfixParseFn(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfixParseFn(token.SLASH, p.parseInfixExpression)
+	p.registerInfixParseFn(token.PERCENT, p.parseInfixExpression)
+	p.registerInfixParseFn(token.POWER, p.parseInfixExpression)
+	p.registerInfixParseFn(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfixParseFn(token.PIPE, p.parseInfixExpression)
+	p.registerInfixParseFn(token.CARET, p.parseInfixExpression)
+	p.registerInfixParseFn(token.SHL, p.parseInfixExpression)
+	p.registerInfixParseFn(token.SHR, p.parseInfixExpression)
 	p.registerInfixParseFn(token.GT, p.parseInfixExpression)
 	p.registerInfixParseFn(token.GTE, p.parseInfixExpression)
 	p.registerInfixParseFn(token.LT, p.parseInfixExpression)
 	p.registerInfixParseFn(token.LTE, p.parseInfixExpression)
 	p.registerInfixParseFn(token.EQ, p.parseInfixExpression)
 	p.registerInfixParseFn(token.NOT_EQ, p.parseInfixExpression)
+	p.registerInfixParseFn(token.AND, p.parseInfixExpression)
+	p.registerInfixParseFn(token.OR, p.parseInfixExpression)
+	// handler for assignment expression
+	p.registerInfixParseFn(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfixParseFn(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParseFn(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParseFn(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParseFn(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParseFn(token.PERCENT_ASSIGN, p.parseAssignExpression)
 	// handler for call expression
 	p.registerInfixParseFn(token.LPAREN, p.parseCallExpression)
 	// handler for index expression
@@ -124,7 +151,7 @@ func (p *parser) ParseProgram(text string) (*ast.Program, []error) {
 
 		// parse a single statement every time
 		stmt, err := p.parseStatment()
-		if err != nil {
+		if err != nil && !reportedIllegal(errs, err) {
 			errs = append(errs, err)
 		}
 
@@ -139,7 +166,19 @@ func (p *parser) ParseProgram(text string) (*ast.Program, []error) {
 	return program, errs
 }
 
-// parseStatment parses a single statement
+// reportedIllegal checks whether an error about an illegal token has already been reported,
+// an illegal token usually breaks both the statement it ends and the statement it starts
+func reportedIllegal(errs []error, err error) bool {
+	if len(errs) == 0 {
+		return false
+	}
+
+	last, ok := errs[len(errs)-1].(*Error)
+	current, ok2 := err.(*Error)
+
+	return ok && ok2 && current.Token.Type == token.ILLEGAL && current.Pos == last.Pos
+}
+
 func (p *parser) parseStatment() (ast.Statement, error) {
 	// TODO: check how to propagate errors when the current token is not a statement indicator
 	// make sure not to produce duplicate errors for the same statement
@@ -151,6 +190,16 @@ func (p *parser) parseStatment() (ast.Statement, error) {
 		stmt, err = p.parseLetStatement()
 	case token.RETURN:
 		stmt, err = p.parseReturnStatement()
+	case token.WHILE:
+		stmt, err = p.parseWhileStatement()
+	case token.FOR:
+		stmt, err = p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		stmt, err = p.parseLoopControlStatement()
+	case token.TRY:
+		stmt, err = p.parseTryStatement()
+	case token.THROW:
+		stmt, err = p.parseThrowStatement()
 	default:
 		stmt, err = p.parseExpressionStatement()
 	}
@@ -166,7 +215,7 @@ func (p *parser) parseStatment() (ast.Statement, error) {
 
 	// illegal statement
 	if !p.peekTokenTypeIs(token.SEMICOLON) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.SEMICOLON)
 	}
 
 	// on successful parsing, we need to consume the last token ;
@@ -178,10 +227,12 @@ func (p *parser) parseStatment() (ast.Statement, error) {
 
 // parseLetStatement parses a single let statement
 func (p *parser) parseLetStatement() (ast.Statement, error) {
+	letToken := p.curToken
+
 	// expect the next token type to be IDENT
 	if !p.peekTokenTypeIs(token.IDENT) {
 		// fail to parse this let statement
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.IDENT)
 	}
 
 	// move forward
@@ -190,7 +241,7 @@ func (p *parser) parseLetStatement() (ast.Statement, error) {
 	// expect the next token type to be IDENT
 	if !p.peekTokenTypeIs(token.ASSIGN) {
 		// fail to parse this let statement
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.ASSIGN)
 	}
 
 	tok := p.curToken
@@ -204,11 +255,19 @@ func (p *parser) parseLetStatement() (ast.Statement, error) {
 		return nil, err
 	}
 
-	return ast.NewLetStatement(ast.NewIdentifierExpression(tok.Literal), value), nil
+	identifier := ast.NewIdentifierExpression(tok.Literal)
+	identifier.Token = tok
+
+	stmt := ast.NewLetStatement(identifier, value)
+	stmt.Token = letToken
+
+	return stmt, nil
 }
 
 // parseReturnStatement parses a single return statement
 func (p *parser) parseReturnStatement() (ast.Statement, error) {
+	returnToken := p.curToken
+
 	// move forward to make p.curToekn be the first token of the expression
 	p.nextToken()
 
@@ -217,7 +276,228 @@ func (p *parser) parseReturnStatement() (ast.Statement, error) {
 		return nil, err
 	}
 
-	return ast.NewReturnStatement(exp), nil
+	stmt := ast.NewReturnStatement(exp)
+	stmt.Token = returnToken
+
+	return stmt, nil
+}
+
+// parseWhileStatement parses a single while statement
+func (p *parser) parseWhileStatement() (ast.Statement, error) {
+	whileToken := p.curToken
+
+	if !p.peekTokenTypeIs(token.LPAREN) {
+		return nil, p.peekError(token.LPAREN)
+	}
+
+	// move forward to make p.curToken point to ( so that we can parse the grouped expressions
+	p.nextToken()
+
+	condition, err := p.parseGroupedExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := ast.NewWhileStatement(condition, body)
+	stmt.Token = whileToken
+
+	return stmt, nil
+}
+
+// parseForStatement parses a single for ... in statement
+func (p *parser) parseForStatement() (ast.Statement, error) {
+	forToken := p.curToken
+
+	if !p.peekTokenTypeIs(token.LPAREN) {
+		return nil, p.peekError(token.LPAREN)
+	}
+
+	// move forward to make p.curToken point to (
+	p.nextToken()
+
+	if !p.peekTokenTypeIs(token.IDENT) {
+		return nil, p.peekError(token.IDENT)
+	}
+
+	// move forward to make p.curToken point to the loop variable
+	p.nextToken()
+
+	identifier := ast.NewIdentifierExpression(p.curToken.Literal)
+	identifier.Token = p.curToken
+
+	if !p.peekTokenTypeIs(token.IN) {
+		return nil, p.peekError(token.IN)
+	}
+
+	// move forward to make p.curToken be the first token of the iterable expression
+	p.nextToken()
+	p.nextToken()
+
+	iterable, err := p.parseExpression(token.LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peekTokenTypeIs(token.RPAREN) {
+		return nil, p.peekError(token.RPAREN)
+	}
+
+	// move forward so that p.curToken points to )
+	p.nextToken()
+
+	body, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := ast.NewForStatement(identifier, iterable, body)
+	stmt.Token = forToken
+
+	return stmt, nil
+}
+
+// parseLoopBody parses the block statement following the header of a loop
+func (p *parser) parseLoopBody() (*ast.BlockStatement, error) {
+	if !p.peekTokenTypeIs(token.LBRACE) {
+		return nil, p.peekError(token.LBRACE)
+	}
+
+	// move forward so that p.curToken points to {
+	p.nextToken()
+
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+
+	return p.parseBlockStatement()
+}
+
+// parseLoopControlStatement parses a single break or continue statement
+func (p *parser) parseLoopControlStatement() (ast.Statement, error) {
+	if p.loopDepth == 0 {
+		return nil, p.curError(ErrLoopControlOutsideLoop)
+	}
+
+	if p.curTokenTypeIs(token.BREAK) {
+		stmt := ast.NewBreakStatement()
+		stmt.Token = p.curToken
+
+		return stmt, nil
+	}
+
+	stmt := ast.NewContinueStatement()
+	stmt.Token = p.curToken
+
+	return stmt, nil
+}
+
+// parseTryStatement parses a single try statement with a catch clause, a finally clause or both
+func (p *parser) parseTryStatement() (ast.Statement, error) {
+	tryToken := p.curToken
+
+	if !p.peekTokenTypeIs(token.LBRACE) {
+		return nil, p.peekError(token.LBRACE)
+	}
+
+	// move forward so that p.curToken points to {
+	p.nextToken()
+
+	block, err := p.parseBlockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	var parameter *ast.IdentifierExpression
+	var catch *ast.BlockStatement
+
+	if p.peekTokenTypeIs(token.CATCH) {
+		// move forward so that p.curToken points to the catch token
+		p.nextToken()
+
+		if !p.peekTokenTypeIs(token.LPAREN) {
+			return nil, p.peekError(token.LPAREN)
+		}
+
+		// move forward so that p.curToken points to (
+		p.nextToken()
+
+		if !p.peekTokenTypeIs(token.IDENT) {
+			return nil, p.peekError(token.IDENT)
+		}
+
+		// move forward so that p.curToken points to the identifier bound to the caught error
+		p.nextToken()
+
+		parameter = ast.NewIdentifierExpression(p.curToken.Literal)
+		parameter.Token = p.curToken
+
+		if !p.peekTokenTypeIs(token.RPAREN) {
+			return nil, p.peekError(token.RPAREN)
+		}
+
+		p.nextToken()
+
+		if !p.peekTokenTypeIs(token.LBRACE) {
+			return nil, p.peekError(token.LBRACE)
+		}
+
+		p.nextToken()
+
+		catch, err = p.parseBlockStatement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var finally *ast.BlockStatement
+
+	if p.peekTokenTypeIs(token.FINALLY) {
+		// move forward so that p.curToken points to the finally token
+		p.nextToken()
+
+		if !p.peekTokenTypeIs(token.LBRACE) {
+			return nil, p.peekError(token.LBRACE)
+		}
+
+		p.nextToken()
+
+		finally, err = p.parseBlockStatement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a try block without any clause is meaningless
+	if catch == nil && finally == nil {
+		return nil, p.peekError(token.CATCH, token.FINALLY)
+	}
+
+	stmt := ast.NewTryStatement(block, parameter, catch, finally)
+	stmt.Token = tryToken
+
+	return stmt, nil
+}
+
+// parseThrowStatement parses a single throw statement
+func (p *parser) parseThrowStatement() (ast.Statement, error) {
+	throwToken := p.curToken
+
+	// move forward to make p.curToken be the first token of the expression
+	p.nextToken()
+
+	value, err := p.parseExpression(token.LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := ast.NewThrowStatement(value)
+	stmt.Token = throwToken
+
+	return stmt, nil
 }
 
 // parseExpressionStatement parses a single expression statement
@@ -234,7 +514,7 @@ func (p *parser) parseExpressionStatement() (ast.Statement, error) {
 func (p *parser) parseExpression(precedence int) (ast.Expression, error) {
 	prefixFn, ok := p.prefixParseFns[p.curToken.Type]
 	if !ok {
-		return nil, ErrPrefixParseFnNotFound
+		return nil, p.curError(ErrPrefixParseFnNotFound)
 	}
 
 	// the prefix expression
@@ -247,7 +527,7 @@ func (p *parser) parseExpression(precedence int) (ast.Expression, error) {
 	for !p.peekTokenTypeIs(token.SEMICOLON) && precedence < token.GetPrecedence(p.peekToken.Type) {
 		infixFn, ok := p.infixParseFns[p.peekToken.Type]
 		if !ok {
-			return exp, ErrInfixParseFnNotFound
+			return exp, p.errorAt(ErrInfixParseFnNotFound, p.peekToken)
 		}
 
 		// move forward to make p.curToekn point to the operator of the infix expression
@@ -263,41 +543,122 @@ func (p *parser) parseExpression(precedence int) (ast.Expression, error) {
 }
 
 func (p *parser) parseIdentifier() (ast.Expression, error) {
-	return ast.NewIdentifierExpression(p.curToken.Literal), nil
+	exp := ast.NewIdentifierExpression(p.curToken.Literal)
+	exp.Token = p.curToken
+
+	return exp, nil
 }
 
 func (p *parser) parseInteger() (ast.Expression, error) {
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, p.curError(err)
+	}
+
+	exp := ast.NewIntegerExpression(p.curToken.Literal, value)
+	exp.Token = p.curToken
+
+	// literals overflowing int64 are kept with arbitrary precision
 	if err != nil {
-		return nil, err
+		exp.Big, _ = new(big.Int).SetString(p.curToken.Literal, 0)
 	}
 
-	return ast.NewIntegerExpression(p.curToken.Literal, value), nil
+	return exp, nil
+}
+
+func (p *parser) parseFloat() (ast.Expression, error) {
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		return nil, p.curError(err)
+	}
+
+	exp := ast.NewFloatExpression(p.curToken.Literal, value)
+	exp.Token = p.curToken
+
+	return exp, nil
 }
 
 func (p *parser) parseBoolean() (ast.Expression, error) {
 	value, err := strconv.ParseBool(p.curToken.Literal)
 	if err != nil {
-		return nil, err
+		return nil, p.curError(err)
 	}
 
-	return ast.NewBooleanExpression(value), nil
+	exp := ast.NewBooleanExpression(value)
+	exp.Token = p.curToken
+
+	return exp, nil
+}
+
+func (p *parser) parseNil() (ast.Expression, error) {
+	exp := ast.NewNilExpression()
+	exp.Token = p.curToken
+
+	return exp, nil
 }
 
 func (p *parser) parseString() (ast.Expression, error) {
-	return ast.NewStringExpression(p.curToken.Literal), nil
+	exp := ast.NewStringExpression(p.curToken.Literal)
+	exp.Token = p.curToken
+
+	return exp, nil
+}
+
+// parseInterpolatedString parses a string with interpolated expressions, e.g. "a ${b} c",
+// the lexer splits the text of the string into a head, middles and a tail around the expressions
+func (p *parser) parseInterpolatedString() (ast.Expression, error) {
+	head := p.curToken
+	strs := []string{head.Literal}
+	exps := []ast.Expression{}
+
+	for !p.curTokenTypeIs(token.STRING_TAIL) {
+		// move forward to make p.curToekn points to the interpolated expression
+		p.nextToken()
+
+		exp, err := p.parseExpression(token.LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		// the expression is closed by a }, which is part of the string middle or tail
+		if !p.peekTokenTypeIs(token.STRING_MIDDLE) && !p.peekTokenTypeIs(token.STRING_TAIL) {
+			return nil, p.peekError(token.RBRACE)
+		}
+
+		p.nextToken()
+
+		exps = append(exps, exp)
+		strs = append(strs, p.curToken.Literal)
+	}
+
+	exp := ast.NewInterpolatedStringExpression(strs, exps)
+	exp.Token = head
+	exp.Rquote = token.Position{
+		Offset: p.curToken.End.Offset - 1,
+		Line:   p.curToken.End.Line,
+		Column: p.curToken.End.Column - 1,
+	}
+
+	return exp, nil
 }
 
 func (p *parser) parseArrayExpression() (ast.Expression, error) {
+	lbracket := p.curToken
+
 	elements, err := p.parseExpressionList(token.RBRACKET)
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.NewArrayExpression(elements...), nil
+	exp := ast.NewArrayExpression(elements...)
+	exp.Token = lbracket
+	exp.Rbracket = p.curToken.Pos
+
+	return exp, nil
 }
 
 func (p *parser) parseHashExpression() (ast.Expression, error) {
+	lbrace := p.curToken
 	items := map[ast.Expression]ast.Expression{}
 
 	for !p.peekTokenTypeIs(token.RBRACE) && !p.peekTokenTypeIs(token.EOF) {
@@ -310,7 +671,7 @@ func (p *parser) parseHashExpression() (ast.Expression, error) {
 		}
 
 		if !p.peekTokenTypeIs(token.COLON) {
-			return nil, ErrUnexpectedTokenType
+			return nil, p.peekError(token.COLON)
 		}
 
 		// move forward to make p.curToken point to the : token
@@ -331,13 +692,17 @@ func (p *parser) parseHashExpression() (ast.Expression, error) {
 	}
 
 	if !p.peekTokenTypeIs(token.RBRACE) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.RBRACE)
 	}
 
 	// move forward so that p.curToken points to the ) token
 	p.nextToken()
 
-	return ast.NewHashExpression(items), nil
+	exp := ast.NewHashExpression(items)
+	exp.Token = lbrace
+	exp.Rbrace = p.curToken.Pos
+
+	return exp, nil
 }
 
 func (p *parser) parseGroupedExpression() (ast.Expression, error) {
@@ -351,7 +716,7 @@ func (p *parser) parseGroupedExpression() (ast.Expression, error) {
 	}
 
 	if !p.peekTokenTypeIs(token.RPAREN) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.RPAREN)
 	}
 
 	// move forward to make p.curToken point to ), do not skip ) because we need to check peek token
@@ -363,6 +728,7 @@ func (p *parser) parseGroupedExpression() (ast.Expression, error) {
 
 func (p *parser) parseBlockStatement() (*ast.BlockStatement, error) {
 	stmts := []ast.Statement{}
+	lbrace := p.curToken
 
 	// skip the { token pointed by p.curToken
 	p.nextToken()
@@ -380,15 +746,21 @@ func (p *parser) parseBlockStatement() (*ast.BlockStatement, error) {
 
 	// illegal, no matching close right brace
 	if !p.curTokenTypeIs(token.RBRACE) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.curError(ErrUnexpectedTokenType, token.RBRACE)
 	}
 
-	return ast.NewBlockStatement(stmts...), nil
+	block := ast.NewBlockStatement(stmts...)
+	block.Token = lbrace
+	block.Rbrace = p.curToken.Pos
+
+	return block, nil
 }
 
 func (p *parser) parseIfExpression() (ast.Expression, error) {
+	ifToken := p.curToken
+
 	if !p.peekTokenTypeIs(token.LPAREN) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.LPAREN)
 	}
 
 	// move forward to make p.curToken point to ( so that we can parse the grouped expressions
@@ -400,7 +772,7 @@ func (p *parser) parseIfExpression() (ast.Expression, error) {
 	}
 
 	if !p.peekTokenTypeIs(token.LBRACE) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.LBRACE)
 	}
 
 	// move forward so that p.curToken point to {
@@ -420,7 +792,7 @@ func (p *parser) parseIfExpression() (ast.Expression, error) {
 		p.nextToken()
 
 		if !p.peekTokenTypeIs(token.LBRACE) {
-			return nil, ErrUnexpectedTokenType
+			return nil, p.peekError(token.LBRACE)
 		}
 
 		// move forward to make p.curToken point to the { token
@@ -435,16 +807,21 @@ func (p *parser) parseIfExpression() (ast.Expression, error) {
 
 	// illegal, no matching close right brace
 	if !p.curTokenTypeIs(token.RBRACE) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.curError(ErrUnexpectedTokenType, token.RBRACE)
 	}
 
-	return ast.NewIfExpression(condition, consequence, alternative), nil
+	exp := ast.NewIfExpression(condition, consequence, alternative)
+	exp.Token = ifToken
+
+	return exp, nil
 }
 
 func (p *parser) parseFuncExpression() (ast.Expression, error) {
+	fnToken := p.curToken
+
 	// expect a ( to follow the fn token
 	if !p.peekTokenTypeIs(token.LPAREN) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.LPAREN)
 	}
 
 	// move forward so that p.curToken points to the ( token
@@ -457,20 +834,29 @@ func (p *parser) parseFuncExpression() (ast.Expression, error) {
 
 	// expect a following { token
 	if !p.peekTokenTypeIs(token.LBRACE) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.LBRACE)
 	}
 
 	// move forward so that p.curToken points to the { token
 	// which is the start of the block statement
 	p.nextToken()
 
+	// loops enclosing the function literal can not be controlled from its body
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+
 	// parse the function body
 	body, err := p.parseBlockStatement()
+	p.loopDepth = loopDepth
+
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.NewFuncExpression(params, body), nil
+	exp := ast.NewFuncExpression(params, body)
+	exp.Token = fnToken
+
+	return exp, nil
 }
 
 func (p *parser) parseFuncParameters() ([]*ast.IdentifierExpression, error) {
@@ -478,7 +864,9 @@ func (p *parser) parseFuncParameters() ([]*ast.IdentifierExpression, error) {
 
 	for !p.peekTokenTypeIs(token.RPAREN) && !p.peekTokenTypeIs(token.EOF) {
 		p.nextToken()
-		params = append(params, ast.NewIdentifierExpression(p.curToken.Literal))
+		param := ast.NewIdentifierExpression(p.curToken.Literal)
+		param.Token = p.curToken
+		params = append(params, param)
 
 		if p.peekTokenTypeIs(token.COMMA) {
 			p.nextToken()
@@ -486,7 +874,7 @@ func (p *parser) parseFuncParameters() ([]*ast.IdentifierExpression, error) {
 	}
 
 	if !p.peekTokenTypeIs(token.RPAREN) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.RPAREN)
 	}
 
 	// move forward so that p.curToken points to the ) token
@@ -507,13 +895,21 @@ func (p *parser) parsePrefixExpression() (ast.Expression, error) {
 		return nil, err
 	}
 
-	return ast.NewPrefixExpression(prefixToken.Literal, operand), nil
+	exp := ast.NewPrefixExpression(prefixToken.Literal, operand)
+	exp.Token = prefixToken
+
+	return exp, nil
 }
 
 func (p *parser) parseInfixExpression(leftOperand ast.Expression) (ast.Expression, error) {
 	operatorToken := p.curToken
 	precedence := token.GetPrecedence(operatorToken.Type)
 
+	// power is right associative, a ** b ** c is parsed as a ** (b ** c)
+	if operatorToken.Type == token.POWER {
+		precedence--
+	}
+
 	// move forward to make p.curToekn points to the right operand expression
 	p.nextToken()
 
@@ -522,7 +918,35 @@ func (p *parser) parseInfixExpression(leftOperand ast.Expression) (ast.Expressio
 		return nil, err
 	}
 
-	return ast.NewInfixExpression(operatorToken.Literal, leftOperand, rightOperand), nil
+	exp := ast.NewInfixExpression(operatorToken.Literal, leftOperand, rightOperand)
+	exp.Token = operatorToken
+
+	return exp, nil
+}
+
+func (p *parser) parseAssignExpression(target ast.Expression) (ast.Expression, error) {
+	// only bindings, array elements and hash entries can be assigned to
+	switch target.(type) {
+	case *ast.IdentifierExpression, *ast.IndexExpression:
+	default:
+		return nil, &Error{Err: ErrInvalidAssignmentTarget, Pos: target.Pos(), Token: p.curToken}
+	}
+
+	operatorToken := p.curToken
+
+	// move forward to make p.curToekn points to the value expression
+	p.nextToken()
+
+	// assignment is right associative, a = b = c is parsed as a = (b = c)
+	value, err := p.parseExpression(token.ASSIGNMENT - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := ast.NewAssignExpression(operatorToken.Literal, target, value)
+	exp.Token = operatorToken
+
+	return exp, nil
 }
 
 func (p *parser) parseCallExpression(leftOperand ast.Expression) (ast.Expression, error) {
@@ -531,7 +955,10 @@ func (p *parser) parseCallExpression(leftOperand ast.Expression) (ast.Expression
 		return nil, err
 	}
 
-	return ast.NewCallExpression(leftOperand, args), nil
+	exp := ast.NewCallExpression(leftOperand, args)
+	exp.Rparen = p.curToken.Pos
+
+	return exp, nil
 }
 
 func (p *parser) parseExpressionList(endTokenType token.TokenType) ([]ast.Expression, error) {
@@ -553,7 +980,7 @@ func (p *parser) parseExpressionList(endTokenType token.TokenType) ([]ast.Expres
 	}
 
 	if !p.peekTokenTypeIs(endTokenType) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(endTokenType)
 	}
 
 	// move forward so that p.curToken points to the ) token
@@ -563,6 +990,13 @@ func (p *parser) parseExpressionList(endTokenType token.TokenType) ([]ast.Expres
 }
 
 func (p *parser) parseIndexExpression(leftOperand ast.Expression) (ast.Expression, error) {
+	lbracket := p.curToken
+
+	// the start of a slice can be omitted, e.g. a[:2]
+	if p.peekTokenTypeIs(token.COLON) {
+		return p.parseSliceExpression(leftOperand, lbracket, nil)
+	}
+
 	// move forward to make p.curToekn points to the index expression
 	p.nextToken()
 
@@ -571,14 +1005,53 @@ func (p *parser) parseIndexExpression(leftOperand ast.Expression) (ast.Expressio
 		return nil, err
 	}
 
+	if p.peekTokenTypeIs(token.COLON) {
+		return p.parseSliceExpression(leftOperand, lbracket, index)
+	}
+
 	if !p.peekTokenTypeIs(token.RBRACKET) {
-		return nil, ErrUnexpectedTokenType
+		return nil, p.peekError(token.RBRACKET)
 	}
 
 	// move forward so that p.curToken points to the ] token
 	p.nextToken()
 
-	return ast.NewIndexExpression(leftOperand, index), nil
+	exp := ast.NewIndexExpression(leftOperand, index)
+	exp.Token = lbracket
+	exp.Rbracket = p.curToken.Pos
+
+	return exp, nil
+}
+
+// parseSliceExpression parses the rest of a slice expression a[low:high] when p.peekToken is the : token
+func (p *parser) parseSliceExpression(leftOperand ast.Expression, lbracket token.Token, low ast.Expression) (ast.Expression, error) {
+	// move forward so that p.curToken points to the : token
+	p.nextToken()
+
+	var high ast.Expression
+
+	// the end of a slice can be omitted, e.g. a[1:]
+	if !p.peekTokenTypeIs(token.RBRACKET) {
+		p.nextToken()
+
+		var err error
+		if high, err = p.parseExpression(token.LOWEST); err != nil {
+			return nil, err
+		}
+
+		if !p.peekTokenTypeIs(token.RBRACKET) {
+			return nil, p.peekError(token.RBRACKET)
+		}
+	}
+
+	// move forward so that p.curToken points to the ] token
+	p.nextToken()
+
+	exp := ast.NewSliceExpression(leftOperand, low, high)
+	exp.Token = lbracket
+	exp.Rbracket = p.curToken.Pos
+
+	return exp, nil
 }
 
 // nextToken uses the lexer to read the next token and mutate the parser's state
@@ -599,7 +1072,37 @@ func (p *parser) peekTokenTypeIs(tokenType token.TokenType) bool {
 	return p.peekToken.Type == tokenType
 }
 
+// errorAt creates a syntax error pointing at a token,
+// a token the lexer could not make sense of is reported with the reason given by the lexer instead
+func (p *parser) errorAt(err error, tok token.Token, expected ...token.TokenType) error {
+	if tok.Type == token.ILLEGAL {
+		return &Error{
+			Err:   p.l.Err(tok),
+			Pos:   tok.Pos,
+			Token: tok,
+		}
+	}
+
+	return &Error{
+		Err:      err,
+		Pos:      tok.Pos,
+		Token:    tok,
+		Expected: expected,
+	}
+}
+
+// curError creates a syntax error pointing at the current token
+func (p *parser) curError(err error, expected ...token.TokenType) error {
+	return p.errorAt(err, p.curToken, expected...)
+}
+
+// peekError creates a syntax error for a peek token not being of the expected type
+func (p *parser) peekError(expected ...token.TokenType) error {
+	return p.errorAt(ErrUnexpectedTokenType, p.peekToken, expected...)
+}
+
 func (p *parser) reset() {
 	p.curToken = token.Token{}
 	p.peekToken = token.Token{}
+	p.loopDepth = 0
 }
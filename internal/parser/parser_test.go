@@ -1,13 +1,31 @@
 package parser_test
 
 import (
+	"errors"
+
 	"github.com/aden-q/monkey/internal/ast"
 	"github.com/aden-q/monkey/internal/lexer"
 	"github.com/aden-q/monkey/internal/parser"
+	"github.com/aden-q/monkey/internal/token"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 )
 
+// ignorePositions compares AST nodes regardless of where they appear in the source text
+var ignorePositions = cmpopts.IgnoreTypes(token.Position{})
+
+// matchErrors succeeds when the errors wrap the expected errors in order
+func matchErrors(expected []error) types.GomegaMatcher {
+	matchers := make([]any, 0, len(expected))
+	for _, err := range expected {
+		matchers = append(matchers, MatchError(err))
+	}
+
+	return HaveExactElements(matchers...)
+}
+
 var _ = Describe("Parser", func() {
 	var (
 		text    string
@@ -40,8 +58,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("integer expressions", func() {
@@ -60,8 +78,82 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("float expressions", func() {
+				text = `
+				3.14;
+				-2.5e-3;
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewExpressionStatement(ast.NewFloatExpression("3.14", 3.14)),
+						ast.NewExpressionStatement(ast.NewPrefixExpression("-", ast.NewFloatExpression("2.5e-3", 0.0025))),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("integer expressions overflowing int64", func() {
+				text = `
+				9223372036854775807;
+				9223372036854775808;
+				`
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(errs).To(matchErrors(expectedErrors))
+				Expect(program.Statements).To(HaveLen(2))
+
+				small := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerExpression)
+				Expect(small.Value).To(Equal(int64(9223372036854775807)))
+				Expect(small.Big).To(BeNil())
+
+				large := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IntegerExpression)
+				Expect(large.Big).NotTo(BeNil())
+				Expect(large.Big.String()).To(Equal("9223372036854775808"))
+			})
+
+			It("interpolated string expressions", func() {
+				text = `
+				"hello ${name}, you have ${len(items)} items";
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewExpressionStatement(ast.NewInterpolatedStringExpression(
+							[]string{"hello ", ", you have ", " items"},
+							[]ast.Expression{
+								ast.NewIdentifierExpression("name"),
+								ast.NewCallExpression(ast.NewIdentifierExpression("len"), []ast.Expression{ast.NewIdentifierExpression("items")}),
+							},
+						)),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+				Expect(program.String()).To(Equal("hello ${name}, you have ${len(items)} items"))
+
+				exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+				Expect(exp.Pos()).To(Equal(token.Position{Offset: 5, Line: 2, Column: 5}))
+				Expect(exp.End()).To(Equal(token.Position{Offset: 50, Line: 2, Column: 50}))
+			})
+
+			It("interpolated expressions must be closed", func() {
+				text = `"a ${1 2}";`
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrUnexpectedTokenType))
+				Expect(errs[0].Error()).To(Equal(`1:8: unexpected token type, got "2", expected }`))
 			})
 
 			It("boolean expressions", func() {
@@ -78,8 +170,26 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("nil expressions", func() {
+				text = `
+				nil;
+				x != nil;
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewExpressionStatement(ast.NewNilExpression()),
+						ast.NewExpressionStatement(ast.NewInfixExpression("!=", ast.NewIdentifierExpression("x"), ast.NewNilExpression())),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("string expressions", func() {
@@ -96,8 +206,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("array expressions", func() {
@@ -112,8 +222,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("hash expressions", func() {
@@ -138,7 +248,7 @@ var _ = Describe("Parser", func() {
 
 				program, errs = p.ParseProgram(text)
 				Expect(len(program.Statements)).To(Equal(len(expectedProgram.Statements)))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("empty hash expressions", func() {
@@ -153,8 +263,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("index expressions", func() {
@@ -169,8 +279,35 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("slice expressions", func() {
+				text = `
+				s[1:n - 1];
+				s[:2];
+				s[-2:];
+				s[:];
+				`
+				s := ast.NewIdentifierExpression("s")
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewExpressionStatement(ast.NewSliceExpression(s, ast.NewIntegerExpression("1", 1), ast.NewInfixExpression("-", ast.NewIdentifierExpression("n"), ast.NewIntegerExpression("1", 1)))),
+						ast.NewExpressionStatement(ast.NewSliceExpression(s, nil, ast.NewIntegerExpression("2", 2))),
+						ast.NewExpressionStatement(ast.NewSliceExpression(s, ast.NewPrefixExpression("-", ast.NewIntegerExpression("2", 2)), nil)),
+						ast.NewExpressionStatement(ast.NewSliceExpression(s, nil, nil)),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(program.String()).To(Equal("(s[1:(n - 1)])(s[:2])(s[(-2):])(s[:])"))
+				Expect(errs).To(matchErrors(expectedErrors))
+
+				_, errs = p.ParseProgram("s[1:2:3];")
+				Expect(errs).To(matchErrors([]error{parser.ErrUnexpectedTokenType}))
 			})
 
 			It("if expressions", func() {
@@ -199,8 +336,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("func expressions", func() {
@@ -236,8 +373,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("call expressions", func() {
@@ -259,8 +396,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("prefix expressions", func() {
@@ -285,8 +422,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("infix expressions", func() {
@@ -331,8 +468,49 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("assign expressions", func() {
+				text = `
+				x = 5;
+				a[0] += 1;
+				x = y = 2 * 3;
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewExpressionStatement(ast.NewAssignExpression("=", ast.NewIdentifierExpression("x"), ast.NewIntegerExpression("5", 5))),
+						ast.NewExpressionStatement(ast.NewAssignExpression("+=",
+							ast.NewIndexExpression(ast.NewIdentifierExpression("a"), ast.NewIntegerExpression("0", 0)),
+							ast.NewIntegerExpression("1", 1),
+						)),
+						ast.NewExpressionStatement(ast.NewAssignExpression("=",
+							ast.NewIdentifierExpression("x"),
+							ast.NewAssignExpression("=",
+								ast.NewIdentifierExpression("y"),
+								ast.NewInfixExpression("*", ast.NewIntegerExpression("2", 2), ast.NewIntegerExpression("3", 3)),
+							),
+						)),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("invalid assignment target", func() {
+				text = `
+				5 = x;
+				`
+				expectedErrors := []error{
+					parser.ErrInvalidAssignmentTarget,
+				}
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("expression string match", func() {
@@ -352,6 +530,16 @@ var _ = Describe("Parser", func() {
 					`a + add(b * c) +d;`,
 					`add(a + b + c * d / f + g);`,
 					`a * [1, 2, 3, 4][b * c] * d;`,
+					`a || b && c == d;`,
+					`a && b || !c;`,
+					`x = a || b;`,
+					`a % b * c;`,
+					`-a ** b;`,
+					`a ** b ** c;`,
+					`a | b ^ c & d;`,
+					`a & b == c;`,
+					`a << b + c;`,
+					`~a & b;`,
 				}
 				expectedStrings := []string{
 					`((-a) * b)`,
@@ -369,6 +557,16 @@ var _ = Describe("Parser", func() {
 					`((a + add((b * c))) + d)`,
 					`add((((a + b) + ((c * d) / f)) + g))`,
 					`((a * ([1, 2, 3, 4][(b * c)])) * d)`,
+					`(a || (b && (c == d)))`,
+					`((a && b) || (!c))`,
+					`(x = (a || b))`,
+					`((a % b) * c)`,
+					`(-(a ** b))`,
+					`(a ** (b ** c))`,
+					`(a | (b ^ (c & d)))`,
+					`((a & b) == c)`,
+					`(a << (b + c))`,
+					`((~a) & b)`,
 				}
 				expectedErrors := []error{}
 
@@ -377,7 +575,7 @@ var _ = Describe("Parser", func() {
 					Expect(program.String()).To(Equal(expectedStrings[idx]))
 				}
 
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 		})
 
@@ -412,8 +610,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("missing identifiers", func() {
@@ -434,8 +632,8 @@ var _ = Describe("Parser", func() {
 				}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 
 			It("missing assign tokens", func() {
@@ -456,8 +654,8 @@ var _ = Describe("Parser", func() {
 				}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 		})
 
@@ -480,12 +678,257 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+		})
+
+		Context("loop statements", func() {
+			It("while statements", func() {
+				text = `
+				while (x < y) { x; break; };
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewWhileStatement(
+							ast.NewInfixExpression("<", ast.NewIdentifierExpression("x"), ast.NewIdentifierExpression("y")),
+							ast.NewBlockStatement(
+								ast.NewExpressionStatement(ast.NewIdentifierExpression("x")),
+								ast.NewBreakStatement(),
+							),
+						),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("for statements", func() {
+				text = `
+				for (x in [1, 2]) { continue; };
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewForStatement(
+							ast.NewIdentifierExpression("x"),
+							ast.NewArrayExpression(ast.NewIntegerExpression("1", 1), ast.NewIntegerExpression("2", 2)),
+							ast.NewBlockStatement(ast.NewContinueStatement()),
+						),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("missing in keyword", func() {
+				text = `
+				for (x [1, 2]) { x; };
+				`
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrUnexpectedTokenType))
+			})
+
+			It("break outside of a loop", func() {
+				text = `
+				break;
+				continue;
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{},
+				}
+				expectedErrors := []error{
+					parser.ErrLoopControlOutsideLoop,
+					parser.ErrLoopControlOutsideLoop,
+				}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("break inside a function inside a loop", func() {
+				text = `
+				while (true) { fn() { break; }; };
+				`
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrLoopControlOutsideLoop))
+			})
+		})
+
+		Context("positions", func() {
+			It("nodes span the source text they are parsed from", func() {
+				text = "let a = [1, 2];\nfoo(a[0] + 1);"
+
+				program, errs = p.ParseProgram(text)
+				Expect(errs).To(BeEmpty())
+				Expect(program.Statements).To(HaveLen(2))
+
+				let := program.Statements[0]
+				Expect(let.Pos()).To(Equal(token.Position{Offset: 0, Line: 1, Column: 1}))
+				Expect(let.End()).To(Equal(token.Position{Offset: 14, Line: 1, Column: 15}))
+
+				call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+				Expect(call.Pos()).To(Equal(token.Position{Offset: 16, Line: 2, Column: 1}))
+				Expect(call.End()).To(Equal(token.Position{Offset: 29, Line: 2, Column: 14}))
+
+				infix := call.Arguments[0]
+				Expect(infix.Pos()).To(Equal(token.Position{Offset: 20, Line: 2, Column: 5}))
+				Expect(infix.End()).To(Equal(token.Position{Offset: 28, Line: 2, Column: 13}))
+			})
+
+			It("errors carry the offending token and the expected alternatives", func() {
+				text = "let a = 1;\nlet = 5;"
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).To(HaveLen(1))
+
+				var parseErr *parser.Error
+				Expect(errors.As(errs[0], &parseErr)).To(BeTrue())
+				Expect(parseErr.Err).To(Equal(parser.ErrUnexpectedTokenType))
+				Expect(parseErr.Pos).To(Equal(token.Position{Offset: 15, Line: 2, Column: 5}))
+				Expect(parseErr.Token.Literal).To(Equal("="))
+				Expect(parseErr.Expected).To(Equal([]token.TokenType{token.IDENT}))
+				Expect(parseErr.Error()).To(Equal(`2:5: unexpected token type, got "=", expected IDENT`))
+			})
+
+			It("illegal tokens are reported once with the reason from the lexer", func() {
+				text = "let s = \"abc;\nlet t = 5;\nlet u = `raw;"
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).To(HaveLen(2))
+				Expect(errs[0]).To(MatchError(lexer.ErrUnterminatedString))
+				Expect(errs[0].Error()).To(Equal(`1:9: unterminated string, got "\"abc;"`))
+				Expect(errs[1]).To(MatchError(lexer.ErrUnterminatedString))
+			})
+
+			It("illegal characters and escape sequences", func() {
+				text = "let x = 1 @ 2;\nlet y = \"\\q\";\n/* open"
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).To(HaveLen(3))
+				Expect(errs[0]).To(MatchError(lexer.ErrIllegalCharacter))
+				Expect(errs[1]).To(MatchError(lexer.ErrInvalidEscape))
+				Expect(errs[2]).To(MatchError(lexer.ErrUnterminatedComment))
+			})
+
+			It("errors at the end of the input", func() {
+				text = "foo(1, 2"
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrUnexpectedTokenType))
+				Expect(errs[0].Error()).To(Equal("1:9: unexpected token type, got end of input, expected )"))
+			})
+		})
+
+		Context("error handling statements", func() {
+			It("try statements", func() {
+				text = `
+				try { x; } catch (e) { e; };
+				try { x; } finally { y; };
+				try { x; } catch (e) { e; } finally { y; };
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewTryStatement(
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("x"))),
+							ast.NewIdentifierExpression("e"),
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("e"))),
+							nil,
+						),
+						ast.NewTryStatement(
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("x"))),
+							nil,
+							nil,
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("y"))),
+						),
+						ast.NewTryStatement(
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("x"))),
+							ast.NewIdentifierExpression("e"),
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("e"))),
+							ast.NewBlockStatement(ast.NewExpressionStatement(ast.NewIdentifierExpression("y"))),
+						),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
+			})
+
+			It("try without catch or finally", func() {
+				text = `
+				try { x; };
+				`
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrUnexpectedTokenType))
+			})
+
+			It("catch without a parameter", func() {
+				text = `
+				try { x; } catch { y; };
+				`
+
+				_, errs = p.ParseProgram(text)
+				Expect(errs).ToNot(BeEmpty())
+				Expect(errs[0]).To(MatchError(parser.ErrUnexpectedTokenType))
+			})
+
+			It("throw statements", func() {
+				text = `
+				throw "boom";
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewThrowStatement(ast.NewStringExpression("boom")),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 		})
 
 		Context("special statements", func() {
+			It("comments are skipped", func() {
+				text = `
+				// the answer
+				let x = 42; # trailing
+				/* block /* nested */ */ x;
+				`
+				expectedProgram := &ast.Program{
+					Statements: []ast.Statement{
+						ast.NewLetStatement(ast.NewIdentifierExpression("x"), ast.NewIntegerExpression("42", 42)),
+						ast.NewExpressionStatement(ast.NewIdentifierExpression("x")),
+					},
+				}
+				expectedErrors := []error{}
+
+				program, errs = p.ParseProgram(text)
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions, cmpopts.IgnoreTypes([]token.Comment{})))
+				Expect(errs).To(matchErrors(expectedErrors))
+
+				// comments are kept on the tokens they precede
+				letStmt := program.Statements[0].(*ast.LetStatement)
+				Expect(letStmt.Token.Comments).To(HaveLen(1))
+				Expect(letStmt.Token.Comments[0].Text).To(Equal("// the answer"))
+			})
+
 			It("empty statement", func() {
 				text = `
 				;
@@ -498,8 +941,8 @@ var _ = Describe("Parser", func() {
 				expectedErrors := []error{}
 
 				program, errs = p.ParseProgram(text)
-				Expect(program).To(Equal(expectedProgram))
-				Expect(errs).To(Equal(expectedErrors))
+				Expect(program).To(BeComparableTo(expectedProgram, ignorePositions))
+				Expect(errs).To(matchErrors(expectedErrors))
 			})
 		})
 	})
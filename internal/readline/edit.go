@@ -0,0 +1,416 @@
+package readline
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// key is a typed character, or one of the special keys below read from an escape sequence
+type key rune
+
+// special keys, negative so that they never collide with characters
+const (
+	keyUnknown key = -(iota + 1)
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+// control characters
+const (
+	keyCtrlA     key = 1
+	keyCtrlB     key = 2
+	keyCtrlC     key = 3
+	keyCtrlD     key = 4
+	keyCtrlE     key = 5
+	keyCtrlF     key = 6
+	keyCtrlG     key = 7
+	keyBackspace key = 8
+	keyTab       key = 9
+	keyLineFeed  key = 10
+	keyCtrlK     key = 11
+	keyEnter     key = 13
+	keyCtrlN     key = 14
+	keyCtrlP     key = 16
+	keyCtrlR     key = 18
+	keyCtrlU     key = 21
+	keyCtrlW     key = 23
+	keyEscape    key = 27
+	keyDeleteBS  key = 127
+)
+
+// lineState is the line being edited
+type lineState struct {
+	prompt string
+	buf    []rune
+	cursor int
+}
+
+// set replaces the line and moves the cursor to its end
+func (s *lineState) set(line string) {
+	s.buf = []rune(line)
+	s.cursor = len(s.buf)
+}
+
+// insert inserts text at the cursor
+func (s *lineState) insert(text ...rune) {
+	buf := make([]rune, 0, len(s.buf)+len(text))
+	buf = append(buf, s.buf[:s.cursor]...)
+	buf = append(buf, text...)
+	buf = append(buf, s.buf[s.cursor:]...)
+
+	s.buf = buf
+	s.cursor += len(text)
+}
+
+// delete removes the characters between two offsets and moves the cursor to the first one
+func (s *lineState) delete(from, to int) {
+	s.buf = append(s.buf[:from], s.buf[to:]...)
+	s.cursor = from
+}
+
+// wordStart finds the start of the word before the cursor
+func (s *lineState) wordStart() int {
+	start := s.cursor
+	for start > 0 && isWordRune(s.buf[start-1]) {
+		start--
+	}
+
+	return start
+}
+
+// isWordRune checks whether a character can be part of a name
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// edit reads a line key by key on a terminal in raw mode
+func (e *editor) edit(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+
+	// the position in the history being shown, len(history) is the line being typed
+	historyIdx := len(e.history)
+	typed := ""
+
+	e.refresh(s)
+
+	for {
+		k, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch k {
+		case keyEnter, keyLineFeed:
+			e.write("\r\n")
+			return string(s.buf), nil
+		case keyCtrlC:
+			e.write("^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(s.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+
+			if s.cursor < len(s.buf) {
+				s.delete(s.cursor, s.cursor+1)
+			}
+		case keyBackspace, keyDeleteBS:
+			if s.cursor > 0 {
+				s.delete(s.cursor-1, s.cursor)
+			}
+		case keyDelete:
+			if s.cursor < len(s.buf) {
+				s.delete(s.cursor, s.cursor+1)
+			}
+		case keyLeft, keyCtrlB:
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case keyRight, keyCtrlF:
+			if s.cursor < len(s.buf) {
+				s.cursor++
+			}
+		case keyHome, keyCtrlA:
+			s.cursor = 0
+		case keyEnd, keyCtrlE:
+			s.cursor = len(s.buf)
+		case keyCtrlK:
+			s.delete(s.cursor, len(s.buf))
+		case keyCtrlU:
+			s.delete(0, s.cursor)
+		case keyCtrlW:
+			start := s.cursor
+			for start > 0 && s.buf[start-1] == ' ' {
+				start--
+			}
+
+			for start > 0 && s.buf[start-1] != ' ' {
+				start--
+			}
+
+			s.delete(start, s.cursor)
+		case keyUp, keyCtrlP:
+			if historyIdx > 0 {
+				// keep the line being typed so that it can be recalled by going down again
+				if historyIdx == len(e.history) {
+					typed = string(s.buf)
+				}
+
+				historyIdx--
+				s.set(e.history[historyIdx])
+			}
+		case keyDown, keyCtrlN:
+			if historyIdx < len(e.history) {
+				historyIdx++
+
+				if historyIdx == len(e.history) {
+					s.set(typed)
+				} else {
+					s.set(e.history[historyIdx])
+				}
+			}
+		case keyCtrlR:
+			submitted, err := e.reverseSearch(s)
+			if err != nil {
+				return "", err
+			}
+
+			if submitted {
+				e.refresh(s)
+				e.write("\r\n")
+
+				return string(s.buf), nil
+			}
+		case keyTab:
+			e.complete(s)
+		default:
+			if k >= ' ' {
+				s.insert(rune(k))
+			}
+		}
+
+		e.refresh(s)
+	}
+}
+
+// reverseSearch searches the history backwards for lines containing the typed text, Ctrl-R moves to the
+// next older match, Enter submits the match, Ctrl-G restores the line and other keys accept the match
+// and are then handled by the line editor, it reports whether the match is submitted
+func (e *editor) reverseSearch(s *lineState) (bool, error) {
+	original := string(s.buf)
+	query := []rune{}
+	match := -1
+
+	// find searches the history for the query, starting at a position and going backwards
+	find := func(from int) {
+		for i := from; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				match = i
+				return
+			}
+		}
+	}
+
+	for {
+		line := ""
+		if match >= 0 {
+			line = e.history[match]
+		}
+
+		e.write(fmt.Sprintf("\r(reverse-i-search)`%s': %s\x1b[K", string(query), line))
+
+		k, err := e.readKey()
+		if err != nil {
+			return false, err
+		}
+
+		switch k {
+		case keyCtrlR:
+			if match > 0 {
+				find(match - 1)
+			}
+		case keyBackspace, keyDeleteBS:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = -1
+				find(len(e.history) - 1)
+			}
+		case keyCtrlG, keyCtrlC:
+			s.set(original)
+			return false, nil
+		case keyEnter, keyLineFeed:
+			if match >= 0 {
+				s.set(line)
+			}
+
+			return true, nil
+		default:
+			if k >= ' ' {
+				query = append(query, rune(k))
+
+				// the current match is kept as long as it contains the longer query
+				start := match
+				if start < 0 {
+					start = len(e.history) - 1
+				}
+
+				match = -1
+				find(start)
+
+				continue
+			}
+
+			if match >= 0 {
+				s.set(line)
+			}
+
+			e.pending = &k
+
+			return false, nil
+		}
+	}
+}
+
+// complete completes the word before the cursor, a single candidate is inserted as a whole,
+// several candidates are completed up to their common prefix or listed when there is none
+func (e *editor) complete(s *lineState) {
+	if e.config.Complete == nil {
+		return
+	}
+
+	start := s.wordStart()
+	word := string(s.buf[start:s.cursor])
+
+	candidates := []string{}
+	seen := map[string]bool{}
+
+	for _, candidate := range e.config.Complete(word) {
+		if strings.HasPrefix(candidate, word) && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.Strings(candidates)
+
+	if len(candidates) == 0 {
+		e.write("\a")
+		return
+	}
+
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			runes := []rune(prefix)
+			prefix = string(runes[:len(runes)-1])
+		}
+	}
+
+	if len(prefix) > len(word) {
+		s.insert([]rune(prefix[len(word):])...)
+		return
+	}
+
+	if len(candidates) > 1 {
+		e.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+	}
+}
+
+// refresh redraws the prompt and the line, and moves the cursor back to its position
+func (e *editor) refresh(s *lineState) {
+	builder := strings.Builder{}
+
+	builder.WriteString("\r")
+	builder.WriteString(s.prompt)
+	builder.WriteString(string(s.buf))
+	builder.WriteString("\x1b[K")
+
+	if n := len(s.buf) - s.cursor; n > 0 {
+		builder.WriteString(fmt.Sprintf("\x1b[%dD", n))
+	}
+
+	e.write(builder.String())
+}
+
+// readKey reads a character, or a special key sent as an escape sequence
+func (e *editor) readKey() (key, error) {
+	if e.pending != nil {
+		k := *e.pending
+		e.pending = nil
+
+		return k, nil
+	}
+
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	if key(r) != keyEscape {
+		return key(r), nil
+	}
+
+	// escape sequences are ESC [ or ESC O followed by parameters and a final character
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	if r != '[' && r != 'O' {
+		return keyUnknown, nil
+	}
+
+	params := []rune{}
+
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+
+		params = append(params, r)
+	}
+
+	switch r {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	case '~':
+		switch string(params) {
+		case "1", "7":
+			return keyHome, nil
+		case "4", "8":
+			return keyEnd, nil
+		case "3":
+			return keyDelete, nil
+		}
+	}
+
+	return keyUnknown, nil
+}
+
+func (e *editor) write(text string) {
+	io.WriteString(e.out, text)
+}
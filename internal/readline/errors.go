@@ -0,0 +1,9 @@
+package readline
+
+import (
+	"errors"
+)
+
+var (
+	ErrInterrupted = errors.New("interrupted")
+)
@@ -0,0 +1,131 @@
+package readline
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// interface compliance check
+var _ Editor = (*editor)(nil)
+
+// Editor reads lines of input, on a terminal the lines can be edited, recalled from the history and completed
+type Editor interface {
+	// ReadLine shows the prompt and reads a line without the trailing newline, it returns io.EOF
+	// at the end of the input and ErrInterrupted when the line is discarded with Ctrl-C
+	ReadLine(prompt string) (string, error)
+	// AddHistory appends a line to the history, blank lines and repeats of the last line are skipped
+	AddHistory(line string)
+	// History returns the history from the oldest line to the newest one
+	History() []string
+	// LoadHistory appends the lines read from r to the history
+	LoadHistory(r io.Reader) error
+	// SaveHistory writes the history to w, one line each
+	SaveHistory(w io.Writer) error
+}
+
+// Terminal switches the input to raw mode so that keys can be read as they are typed
+type Terminal interface {
+	// MakeRaw switches to raw mode and returns a function restoring the previous mode
+	MakeRaw() (func() error, error)
+}
+
+type Config struct {
+	// the maximum number of lines kept in the history, the oldest lines are dropped first
+	MaxHistory int
+	// Complete returns the candidates to complete the word before the cursor with
+	Complete func(word string) []string
+	// the terminal of the input, it is detected from the input when nil,
+	// lines are read without editing when the input is not a terminal
+	Terminal Terminal
+}
+
+type editor struct {
+	in     *bufio.Reader
+	out    io.Writer
+	config Config
+	term   Terminal
+
+	history []string
+	// a key read by the search that ended it, handled again by the line editor
+	pending *key
+}
+
+func New(in io.Reader, out io.Writer, config Config) Editor {
+	term := config.Terminal
+	if term == nil {
+		term = terminalOf(in)
+	}
+
+	return &editor{
+		in:     bufio.NewReader(in),
+		out:    out,
+		config: config,
+		term:   term,
+	}
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	if e.term == nil {
+		return e.readPlainLine(prompt)
+	}
+
+	restore, err := e.term.MakeRaw()
+	if err != nil {
+		return e.readPlainLine(prompt)
+	}
+	defer restore()
+
+	return e.edit(prompt)
+}
+
+// readPlainLine reads a line as is, for input that is not typed on a terminal
+func (e *editor) readPlainLine(prompt string) (string, error) {
+	io.WriteString(e.out, prompt)
+
+	line, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+func (e *editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" || e.config.MaxHistory <= 0 {
+		return
+	}
+
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+
+	if len(e.history) > e.config.MaxHistory {
+		e.history = e.history[len(e.history)-e.config.MaxHistory:]
+	}
+}
+
+func (e *editor) History() []string {
+	return append([]string{}, e.history...)
+}
+
+func (e *editor) LoadHistory(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		e.AddHistory(scanner.Text())
+	}
+
+	return scanner.Err()
+}
+
+func (e *editor) SaveHistory(w io.Writer) error {
+	for _, line := range e.history {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
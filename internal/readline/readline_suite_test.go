@@ -0,0 +1,13 @@
+package readline_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReadline(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Readline Suite")
+}
@@ -0,0 +1,193 @@
+package readline_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aden-q/monkey/internal/readline"
+)
+
+// fakeTerminal pretends the input is a terminal so that lines are edited
+type fakeTerminal struct {
+	raw int
+}
+
+func (t *fakeTerminal) MakeRaw() (func() error, error) {
+	t.raw++
+
+	return func() error {
+		t.raw--
+		return nil
+	}, nil
+}
+
+var _ = Describe("Readline", func() {
+	var (
+		out  *bytes.Buffer
+		term *fakeTerminal
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		term = &fakeTerminal{}
+	})
+
+	newEditor := func(input string, config readline.Config) readline.Editor {
+		config.Terminal = term
+		return readline.New(strings.NewReader(input), out, config)
+	}
+
+	Describe("plain input", func() {
+		It("reads lines as is when the input is not a terminal", func() {
+			e := readline.New(strings.NewReader("let x = 1;\r\nx\x1b[D;"), out, readline.Config{})
+
+			line, err := e.ReadLine(">>> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("let x = 1;"))
+
+			line, err = e.ReadLine(">>> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("x\x1b[D;"))
+
+			_, err = e.ReadLine(">>> ")
+			Expect(err).To(Equal(io.EOF))
+			Expect(out.String()).To(Equal(">>> >>> >>> "))
+		})
+	})
+
+	Describe("line editing", func() {
+		It("inserts and deletes at the cursor", func() {
+			e := newEditor("abd\x1b[Dc\x1b[C\x7fe\x01x\x7f\x1b[3~A\x05!\r", readline.Config{})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("Abce!"))
+			Expect(term.raw).To(Equal(0))
+		})
+
+		It("kills text before and after the cursor", func() {
+			e := newEditor("let x = 1; junk\x17\x17\x01\x0bfoo bar\x02\x02\x02\x15\r", readline.Config{})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("bar"))
+		})
+
+		It("handles unicode characters", func() {
+			e := newEditor("héllo\x1b[D\x1b[D\x7f\r", readline.Config{})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("hélo"))
+		})
+
+		It("redraws the line and moves the cursor back", func() {
+			e := newEditor("ab\x1b[D\r", readline.Config{})
+
+			_, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(HaveSuffix("\r> ab\x1b[K\x1b[1D\r\n"))
+		})
+
+		It("Ctrl-C discards the line and Ctrl-D ends the input", func() {
+			e := newEditor("abc\x03\x04", readline.Config{})
+
+			_, err := e.ReadLine("> ")
+			Expect(err).To(MatchError(readline.ErrInterrupted))
+
+			_, err = e.ReadLine("> ")
+			Expect(err).To(Equal(io.EOF))
+		})
+	})
+
+	Describe("history", func() {
+		It("is capped at the maximum size and skips blank and repeated lines", func() {
+			e := newEditor("", readline.Config{MaxHistory: 2})
+
+			for _, line := range []string{"a", "b", "b", "  ", "c"} {
+				e.AddHistory(line)
+			}
+
+			Expect(e.History()).To(Equal([]string{"b", "c"}))
+		})
+
+		It("navigates with the up and down keys", func() {
+			e := newEditor("\x1b[A\x1b[A\x1b[A\r"+"new\x1b[A\x1b[B\r", readline.Config{MaxHistory: 10})
+			e.AddHistory("first")
+			e.AddHistory("second")
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("first"))
+
+			line, err = e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("new"))
+		})
+
+		It("searches backwards with Ctrl-R", func() {
+			e := newEditor("\x12let\r"+"\x12let\x12\x1b[D!\r"+"typed\x12zzz\x07\r", readline.Config{MaxHistory: 10})
+			e.AddHistory("let x = 1;")
+			e.AddHistory("print(x);")
+			e.AddHistory("let y = 2;")
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("let y = 2;"))
+
+			line, err = e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("let x = 1!;"))
+
+			line, err = e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("typed"))
+		})
+
+		It("is saved and loaded one line each", func() {
+			e := newEditor("", readline.Config{MaxHistory: 10})
+			Expect(e.LoadHistory(strings.NewReader("a\nb\n\nc\n"))).To(Succeed())
+			Expect(e.History()).To(Equal([]string{"a", "b", "c"}))
+
+			saved := &bytes.Buffer{}
+			Expect(e.SaveHistory(saved)).To(Succeed())
+			Expect(saved.String()).To(Equal("a\nb\nc\n"))
+		})
+	})
+
+	Describe("completion", func() {
+		complete := func(word string) []string {
+			return []string{"print", "push", "len", "let", "let"}
+		}
+
+		It("completes a single candidate", func() {
+			e := newEditor("x + le\tn\t(\r", readline.Config{Complete: complete})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("x + len("))
+		})
+
+		It("completes the common prefix and lists the candidates", func() {
+			e := newEditor("p\t\t\r", readline.Config{Complete: complete})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("p"))
+			Expect(out.String()).To(ContainSubstring("\r\nprint  push\r\n"))
+		})
+
+		It("rings the bell without candidates", func() {
+			e := newEditor("zz\t\r", readline.Config{Complete: complete})
+
+			line, err := e.ReadLine("> ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("zz"))
+			Expect(out.String()).To(ContainSubstring("\a"))
+		})
+	})
+})
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package readline
+
+import "io"
+
+// terminalOf returns nil, line editing is not supported on this platform
+func terminalOf(in io.Reader) Terminal {
+	return nil
+}
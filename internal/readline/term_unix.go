@@ -0,0 +1,55 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package readline
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// fdTerminal is a terminal device the input is read from
+type fdTerminal struct {
+	fd int
+}
+
+// terminalOf returns the terminal of the input, or nil when the input is not a terminal
+func terminalOf(in io.Reader) Terminal {
+	f, ok := in.(*os.File)
+	if !ok {
+		return nil
+	}
+
+	if _, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios); err != nil {
+		return nil
+	}
+
+	return &fdTerminal{fd: int(f.Fd())}
+}
+
+func (t *fdTerminal) MakeRaw() (func() error, error) {
+	termios, err := unix.IoctlGetTermios(t.fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *termios
+
+	// the same settings as cfmakeraw(3)
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(t.fd, ioctlSetTermios, termios); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return unix.IoctlSetTermios(t.fd, ioctlSetTermios, &previous)
+	}, nil
+}
@@ -0,0 +1,174 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aden-q/monkey/internal/token"
+)
+
+// commands start with a colon so that they never collide with Monkey statements
+const COMMAND_PREFIX = ":"
+
+// command is a REPL meta-command, e.g. :help
+type command struct {
+	name string
+	// the argument the command expects, empty when it takes none
+	arg  string
+	help string
+	// run runs the command with the rest of the line, it reports whether the session should end
+	run func(s *session, arg string) (bool, error)
+}
+
+var commands []command
+
+func init() {
+	// assigned in init because :help refers to the table itself
+	commands = []command{
+		{name: "help", help: "show this help", run: runHelp},
+		{name: "env", help: "list the bindings and the types of their values", run: runEnv},
+		{name: "tokens", arg: "<expr>", help: "show the tokens of an expression", run: runTokens},
+		{name: "ast", arg: "<expr>", help: "show the syntax tree of an expression", run: runAST},
+		{name: "load", arg: "<file>", help: "evaluate a source file in the session", run: runLoad},
+		{name: "save", arg: "<file>", help: "write the inputs evaluated so far to a file", run: runSave},
+		{name: "reset", help: "drop all bindings and inputs", run: runReset},
+		{name: "time", arg: "<expr>", help: "evaluate an expression and show how long it took", run: runTime},
+		{name: "quit", help: "leave the REPL", run: runQuit},
+	}
+}
+
+// isCommand checks whether a line is a meta-command rather than Monkey source
+func isCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), COMMAND_PREFIX)
+}
+
+// runCommand runs the meta-command on a line, it reports whether the session should end
+func (s *session) runCommand(line string) bool {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), COMMAND_PREFIX), " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+
+		if c.arg != "" && arg == "" {
+			fmt.Fprintf(s.out, "Error: %v: usage %s%s %s\n", ErrMissingArgument, COMMAND_PREFIX, c.name, c.arg)
+			return false
+		}
+
+		quit, err := c.run(s, arg)
+		if err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+
+		return quit
+	}
+
+	fmt.Fprintf(s.out, "Error: %v %s%s, try %shelp\n", ErrUnknownCommand, COMMAND_PREFIX, name, COMMAND_PREFIX)
+
+	return false
+}
+
+func runHelp(s *session, _ string) (bool, error) {
+	for _, c := range commands {
+		usage := COMMAND_PREFIX + c.name
+		if c.arg != "" {
+			usage += " " + c.arg
+		}
+
+		fmt.Fprintf(s.out, "  %-16s%s\n", usage, c.help)
+	}
+
+	return false, nil
+}
+
+func runEnv(s *session, _ string) (bool, error) {
+	names := s.env.Keys()
+	sort.Strings(names)
+
+	for _, name := range names {
+		obj, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s: %s\n", name, obj.Type())
+	}
+
+	return false, nil
+}
+
+func runTokens(s *session, src string) (bool, error) {
+	s.lexer.Read(src)
+
+	for {
+		tok := s.lexer.NextToken()
+		if tok.Type == token.EOF {
+			return false, nil
+		}
+
+		fmt.Fprintf(s.out, "%s\t%s\t%q", tok.Pos, tok.Type, tok.Literal)
+
+		if err := s.lexer.Err(tok); err != nil {
+			fmt.Fprintf(s.out, "\t%v", err)
+		}
+
+		fmt.Fprintln(s.out)
+	}
+}
+
+func runAST(s *session, src string) (bool, error) {
+	program, errs := s.parser.ParseProgram(src)
+	if len(errs) != 0 {
+		printParserErrors(s.out, src, errs)
+		return false, nil
+	}
+
+	writeTree(s.out, program)
+
+	return false, nil
+}
+
+func runLoad(s *session, path string) (bool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	s.eval(string(src))
+
+	return false, nil
+}
+
+func runSave(s *session, path string) (bool, error) {
+	src := strings.Join(s.inputs, "\n")
+	if src != "" {
+		src += "\n"
+	}
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.inputs), path)
+
+	return false, nil
+}
+
+func runReset(s *session, _ string) (bool, error) {
+	s.reset()
+
+	return false, nil
+}
+
+func runTime(s *session, src string) (bool, error) {
+	start := time.Now()
+	s.eval(src)
+	fmt.Fprintf(s.out, "took %v\n", time.Since(start))
+
+	return false, nil
+}
+
+func runQuit(_ *session, _ string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,10 @@
+package repl
+
+import (
+	"errors"
+)
+
+var (
+	ErrUnknownCommand  = errors.New("unknown command")
+	ErrMissingArgument = errors.New("missing argument")
+)
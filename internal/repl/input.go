@@ -0,0 +1,48 @@
+package repl
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/token"
+)
+
+// NeedsMoreInput checks whether the input read so far is an incomplete statement, i.e. it has unclosed
+// parentheses, brackets, braces, interpolations, raw strings or block comments, or it misses the trailing ;
+func NeedsMoreInput(src string) bool {
+	l := lexer.New()
+	l.Read(src)
+
+	depth := 0
+	last := token.Token{Type: token.SEMICOLON}
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE, token.STRING_HEAD:
+			depth++
+		case token.RPAREN, token.RBRACKET, token.RBRACE, token.STRING_TAIL:
+			depth--
+		case token.ILLEGAL:
+			err := l.Err(tok)
+
+			// raw strings and block comments can span multiple lines, double-quoted strings can not
+			if errors.Is(err, lexer.ErrUnterminatedComment) ||
+				(errors.Is(err, lexer.ErrUnterminatedString) && strings.HasPrefix(tok.Literal, "`")) {
+				return true
+			}
+
+			// other illegal tokens are reported by the parser right away
+			return false
+		}
+
+		last = tok
+	}
+
+	if depth < 0 {
+		// unbalanced closing delimiters can never be completed
+		return false
+	}
+
+	return depth > 0 || last.Type != token.SEMICOLON
+}
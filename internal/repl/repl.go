@@ -1,18 +1,23 @@
 package repl
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"strings"
 
-	"github.com/aden-q/monkey/internal/evaluator"
-	"github.com/aden-q/monkey/internal/lexer"
 	"github.com/aden-q/monkey/internal/object"
-	"github.com/aden-q/monkey/internal/parser"
+	"github.com/aden-q/monkey/internal/readline"
+	"github.com/aden-q/monkey/internal/token"
 )
 
 const PROMPT = ">>> "
 
+// the prompt shown while the input read so far is an incomplete statement
+const CONTINUATION_PROMPT = "... "
+
 const MONKEY_FACE = `            __,__
    .--.  .-"     "-.  .--.
   / .. \/  .-. .-.  \/ .. \
@@ -32,63 +37,136 @@ type REPL interface {
 
 type Config struct {
 	MaxHistory int
+	// the file the history is loaded from and saved to, empty to keep it in memory only
+	HistoryFile string
 }
 
 type repl struct {
-	// command history is a fixed size buffer that stores the last N commands
-	history []string
+	config Config
 }
 
 func New(config Config) REPL {
 	return &repl{
-		history: make([]string, 0, config.MaxHistory),
+		config: config,
 	}
 }
 
 func (r *repl) Start(in io.ReadCloser, out io.WriteCloser, userName string) {
-	scanner := bufio.NewScanner(in)
-	l := lexer.New()
-	p := parser.New(l)
-	e := evaluator.New(object.NewEnvironment())
+	s := newSession(out)
+
+	editor := readline.New(in, out, readline.Config{
+		MaxHistory: r.config.MaxHistory,
+		Complete: func(word string) []string {
+			return completions(s.env, word)
+		},
+	})
+
+	r.loadHistory(editor, out)
+	defer r.saveHistory(editor, out)
 
-	fmt.Print(MONKEY_FACE)
-	fmt.Printf("Hello %s! This is the Monkey programming language!\n", userName)
+	fmt.Fprint(out, MONKEY_FACE)
+	fmt.Fprintf(out, "Hello %s! This is the Monkey programming language!\n", userName)
+
+	// the lines of the statement being read, a statement can span multiple lines
+	lines := []string{}
 
 	for {
-		fmt.Print(PROMPT)
+		prompt := PROMPT
+		if len(lines) != 0 {
+			prompt = CONTINUATION_PROMPT
+		}
 
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := editor.ReadLine(prompt)
+		if errors.Is(err, readline.ErrInterrupted) {
+			// Ctrl-C discards the statement being read
+			lines = lines[:0]
+			continue
+		}
+
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		// TODO: use the history list to navigate through the command history
-		_ = append(r.history, line)
+		editor.AddHistory(line)
+
+		if len(lines) == 0 && isCommand(line) {
+			if s.runCommand(line) {
+				return
+			}
 
-		program, errs := p.ParseProgram(line)
-		if len(errs) != 0 {
-			printParserErrors(out, errs)
 			continue
 		}
 
-		res, err := e.Eval(program)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			continue
+		// an empty line submits an incomplete statement as is, so that its errors are reported
+		if len(lines) == 0 || strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+
+			if NeedsMoreInput(strings.Join(lines, "\n")) {
+				continue
+			}
 		}
 
-		// TODO: PrettyPrint
-		if res != object.NIL {
-			fmt.Println(res.Inspect())
+		src := strings.Join(lines, "\n")
+		lines = lines[:0]
+
+		s.eval(src)
+	}
+}
+
+// completions lists the keywords, builtins and names bound in the environment that start with a word
+func completions(env object.Environment, word string) []string {
+	names := token.Keywords()
+	for name := range object.BuiltinFuncs {
+		names = append(names, name)
+	}
+
+	names = append(names, env.Keys()...)
+
+	candidates := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	return candidates
+}
+
+// loadHistory reads the history file, a missing file is an empty history
+func (r *repl) loadHistory(editor readline.Editor, out io.Writer) {
+	if r.config.HistoryFile == "" {
+		return
+	}
+
+	f, err := os.Open(r.config.HistoryFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(out, "history load error: %v\n", err)
 		}
+
+		return
+	}
+	defer f.Close()
+
+	if err := editor.LoadHistory(f); err != nil {
+		fmt.Fprintf(out, "history load error: %v\n", err)
 	}
 }
 
-func printParserErrors(out io.WriteCloser, errs []error) {
-	fmt.Println("parser errors:")
+// saveHistory writes the history back to the history file
+func (r *repl) saveHistory(editor readline.Editor, out io.Writer) {
+	if r.config.HistoryFile == "" {
+		return
+	}
+
+	f, err := os.OpenFile(r.config.HistoryFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		fmt.Fprintf(out, "history save error: %v\n", err)
+		return
+	}
+	defer f.Close()
 
-	for _, err := range errs {
-		fmt.Println("\t" + err.Error())
+	if err := editor.SaveHistory(f); err != nil {
+		fmt.Fprintf(out, "history save error: %v\n", err)
 	}
 }
@@ -1,6 +1,12 @@
 package repl_test
 
 import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/aden-q/monkey/internal/repl"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,4 +17,125 @@ var _ = Describe("Repl", func() {
 		r := repl.New(repl.Config{})
 		Expect(r).ToNot(BeNil())
 	})
+
+	Describe("NeedsMoreInput", func() {
+		It("complete statements", func() {
+			Expect(repl.NeedsMoreInput("")).To(BeFalse())
+			Expect(repl.NeedsMoreInput("let x = 5;")).To(BeFalse())
+			Expect(repl.NeedsMoreInput("let add = fn(x, y) {\n  return x + y;\n};")).To(BeFalse())
+			Expect(repl.NeedsMoreInput("let s = `a\nb`; // done")).To(BeFalse())
+		})
+
+		It("unclosed delimiters", func() {
+			Expect(repl.NeedsMoreInput("let add = fn(x, y) {")).To(BeTrue())
+			Expect(repl.NeedsMoreInput("add(1,")).To(BeTrue())
+			Expect(repl.NeedsMoreInput("let arr = [1, 2")).To(BeTrue())
+			Expect(repl.NeedsMoreInput(`"a ${ fn() {`)).To(BeTrue())
+		})
+
+		It("missing trailing semicolon", func() {
+			Expect(repl.NeedsMoreInput("let x = 5")).To(BeTrue())
+			Expect(repl.NeedsMoreInput("if (x) { 1; }")).To(BeTrue())
+		})
+
+		It("raw strings and block comments spanning multiple lines", func() {
+			Expect(repl.NeedsMoreInput("let s = `first line")).To(BeTrue())
+			Expect(repl.NeedsMoreInput("/* a comment")).To(BeTrue())
+		})
+
+		It("input that can never be completed is submitted", func() {
+			Expect(repl.NeedsMoreInput(`let s = "abc`)).To(BeFalse())
+			Expect(repl.NeedsMoreInput("let x = 1 @ 2")).To(BeFalse())
+			Expect(repl.NeedsMoreInput("x);")).To(BeFalse())
+			Expect(repl.NeedsMoreInput("x)")).To(BeFalse())
+		})
+	})
+
+	// start runs a REPL session over the input and returns what it wrote
+	start := func(input string) string {
+		out := &bytes.Buffer{}
+		repl.New(repl.Config{}).Start(io.NopCloser(strings.NewReader(input)), nopWriteCloser{out}, "test")
+
+		return out.String()
+	}
+
+	It("writes everything to the given writer", func() {
+		out := start("let x = 1 +\n2;\nprint(\"x =\", x);\nx;\n1 / 0;\nlet;\n")
+		Expect(out).To(HavePrefix(repl.MONKEY_FACE + "Hello test! This is the Monkey programming language!\n"))
+		Expect(out).To(ContainSubstring(">>> ... >>> x = 3\n>>> 3\n>>> Error: 1:1: division by zero\n"))
+		Expect(out).To(ContainSubstring("parser errors:\n"))
+	})
+
+	Describe("commands", func() {
+		It(":env lists the bindings and their types", func() {
+			out := start("let x = 1;\nlet name = \"monkey\";\n:env\n")
+			Expect(out).To(ContainSubstring("name: STRING\nx: INTEGER\n"))
+		})
+
+		It(":tokens shows the lexer output", func() {
+			out := start(":tokens x + 1 @\n")
+			Expect(out).To(ContainSubstring("1:1\tIDENT\t\"x\"\n1:3\t+\t\"+\"\n1:5\tINT\t\"1\"\n1:7\tILLEGAL\t\"@\"\tillegal character\n"))
+		})
+
+		It(":ast shows the syntax tree", func() {
+			out := start(":ast -a + 2;\n")
+			Expect(out).To(ContainSubstring(`Program 1:1
+  Statements[0]: ExpressionStatement 1:1
+    Expression: InfixExpression 1:1 Operator="+"
+      LeftOperand: PrefixExpression 1:1 Operator="-"
+        Operand: IdentifierExpression 1:2 Value="a"
+      RightOperand: IntegerExpression 1:6 Value=2
+`))
+		})
+
+		It(":ast shows the value of integer literals overflowing int64", func() {
+			out := start(":ast 18446744073709551616;\n")
+			Expect(out).To(ContainSubstring("Expression: IntegerExpression 1:1 Value=18446744073709551616\n"))
+		})
+
+		It(":save writes the inputs that succeeded and :load evaluates them", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "session.mk")
+
+			out := start("let x = 2;\nlet y = fn(a) {\n  a * x;\n};\nundefined;\n:save " + path + "\n")
+			Expect(out).To(ContainSubstring("saved 2 inputs to " + path))
+
+			src, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(src)).To(Equal("let x = 2;\nlet y = fn(a) {\n  a * x;\n};\n"))
+
+			out = start(":load " + path + "\n:env\n")
+			Expect(out).To(ContainSubstring("x: INTEGER\ny: FUNCTION\n"))
+		})
+
+		It(":reset drops the bindings", func() {
+			out := start("let x = 1;\n:reset\n:env\n:load\n")
+			Expect(out).NotTo(ContainSubstring("x: INTEGER"))
+			Expect(out).To(ContainSubstring("Error: missing argument: usage :load <file>\n"))
+		})
+
+		It(":quit ends the session and unknown commands are reported", func() {
+			out := start(":nope\n:quit\n:env\nlet x = 1;\n:env\n")
+			Expect(out).To(ContainSubstring("Error: unknown command :nope, try :help\n"))
+			Expect(out).NotTo(ContainSubstring("x: INTEGER"))
+		})
+
+		It(":help lists the commands", func() {
+			out := start(":help\n")
+			for _, name := range []string{":help", ":env", ":tokens <expr>", ":ast <expr>", ":load <file>", ":save <file>", ":reset", ":time <expr>", ":quit"} {
+				Expect(out).To(ContainSubstring("  " + name))
+			}
+		})
+
+		It(":time shows how long the evaluation took", func() {
+			Expect(start(":time 1 + 1;\n")).To(MatchRegexp(`took \S+s\n`))
+		})
+	})
 })
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aden-q/monkey/internal/diagnostic"
+	"github.com/aden-q/monkey/internal/evaluator"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+)
+
+// session is the state shared by the statements and commands of a REPL run
+type session struct {
+	out io.Writer
+
+	lexer     lexer.Lexer
+	parser    parser.Parser
+	env       object.Environment
+	evaluator evaluator.Evaluator
+
+	// the inputs evaluated without errors, in order, so that :save can replay the session
+	inputs []string
+}
+
+func newSession(out io.Writer) *session {
+	l := lexer.New()
+	s := &session{
+		out:    out,
+		lexer:  l,
+		parser: parser.New(l),
+	}
+
+	s.reset()
+
+	return s
+}
+
+// reset drops all the bindings and the recorded inputs
+func (s *session) reset() {
+	s.env = object.NewEnvironment()
+	s.evaluator = evaluator.NewWithOutput(s.env, s.out)
+	s.inputs = nil
+}
+
+// eval parses and evaluates a source text and prints its result or errors,
+// it reports whether the evaluation succeeded
+func (s *session) eval(src string) bool {
+	program, errs := s.parser.ParseProgram(src)
+	if len(errs) != 0 {
+		printParserErrors(s.out, src, errs)
+		return false
+	}
+
+	res, err := s.evaluator.Eval(program)
+	if err != nil {
+		fmt.Fprintf(s.out, "Error: %v\n", err)
+		diagnostic.WriteSnippet(s.out, "\t", src, err)
+		diagnostic.WriteStackTrace(s.out, "\t", err)
+		return false
+	}
+
+	s.inputs = append(s.inputs, src)
+
+	// TODO: PrettyPrint
+	if res != object.NIL {
+		fmt.Fprintln(s.out, res.Inspect())
+	}
+
+	return true
+}
+
+func printParserErrors(w io.Writer, src string, errs []error) {
+	fmt.Fprintln(w, "parser errors:")
+
+	for _, err := range errs {
+		fmt.Fprintln(w, "\t"+err.Error())
+		diagnostic.WriteSnippet(w, "\t", src, err)
+	}
+}
@@ -0,0 +1,98 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aden-q/monkey/internal/ast"
+	"github.com/aden-q/monkey/internal/token"
+)
+
+var (
+	nodeType     = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	tokenType    = reflect.TypeOf(token.Token{})
+	positionType = reflect.TypeOf(token.Position{})
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+)
+
+// writeTree writes a syntax tree one node per line, children are indented under their parent
+// and labelled with the field they are stored in
+func writeTree(w io.Writer, node ast.Node) {
+	writeNode(w, "", "", node)
+}
+
+func writeNode(w io.Writer, indent, label string, node ast.Node) {
+	v := reflect.ValueOf(node)
+	if !v.IsValid() || v.IsNil() {
+		return
+	}
+
+	v = v.Elem()
+
+	type child struct {
+		label string
+		node  ast.Node
+	}
+
+	attrs := []string{}
+	children := []child{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+
+		switch {
+		case field.Type == tokenType || field.Type == positionType:
+			// positions are shown next to the node type instead
+		case field.Type.Implements(nodeType):
+			if !value.IsNil() {
+				children = append(children, child{field.Name, value.Interface().(ast.Node)})
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Implements(nodeType):
+			for j := 0; j < value.Len(); j++ {
+				children = append(children, child{fmt.Sprintf("%s[%d]", field.Name, j), value.Index(j).Interface().(ast.Node)})
+			}
+		case field.Type.Kind() == reflect.Map && field.Type.Key().Implements(nodeType):
+			// map entries are written in source order
+			keys := value.MapKeys()
+			sort.Slice(keys, func(a, b int) bool {
+				return keys[a].Interface().(ast.Node).Pos().Offset < keys[b].Interface().(ast.Node).Pos().Offset
+			})
+
+			for j, key := range keys {
+				children = append(children,
+					child{fmt.Sprintf("%s[%d].Key", field.Name, j), key.Interface().(ast.Node)},
+					child{fmt.Sprintf("%s[%d].Value", field.Name, j), value.MapIndex(key).Interface().(ast.Node)},
+				)
+			}
+		case field.Type == bigIntType:
+			// the value of an integer literal overflowing int64 is shown instead of its clamped int64 value
+			if !value.IsNil() {
+				attrs = slices.DeleteFunc(attrs, func(attr string) bool { return strings.HasPrefix(attr, "Value=") })
+				attrs = append(attrs, fmt.Sprintf("Value=%v", value.Interface()))
+			}
+		default:
+			attrs = append(attrs, fmt.Sprintf("%s=%#v", field.Name, value.Interface()))
+		}
+	}
+
+	line := indent
+	if label != "" {
+		line += label + ": "
+	}
+
+	line += v.Type().Name() + " " + node.Pos().String()
+	if len(attrs) != 0 {
+		line += " " + strings.Join(attrs, " ")
+	}
+
+	fmt.Fprintln(w, line)
+
+	for _, c := range children {
+		writeNode(w, indent+"  ", c.label, c.node)
+	}
+}
@@ -1,15 +1,33 @@
 package setting
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
+// the file the REPL history is kept in, relative to the home directory
+const defaultHistoryFile = ".monkey_history"
+
 type Setting struct {
 	MaxHistory int `envconfig:"MAX_HISTORY" default:"1000"`
+	// an empty history file keeps the history in memory only
+	HistoryFile string `envconfig:"HISTORY_FILE"`
 }
 
 func Load() (Setting, error) {
 	var s Setting
 	err := envconfig.Process("", &s)
-	return s, err
+	if err != nil {
+		return s, err
+	}
+
+	if _, ok := os.LookupEnv("HISTORY_FILE"); !ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			s.HistoryFile = filepath.Join(home, defaultHistoryFile)
+		}
+	}
+
+	return s, nil
 }
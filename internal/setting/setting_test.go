@@ -1,6 +1,8 @@
 package setting_test
 
 import (
+	"path/filepath"
+
 	"github.com/aden-q/monkey/internal/setting"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -16,4 +18,26 @@ var _ = Describe("Setting", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(config.MaxHistory).To(Equal(10))
 	})
+
+	It("history file defaults to the home directory", func() {
+		GinkgoT().Setenv("HOME", "/home/monkey")
+
+		config, err := setting.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.HistoryFile).To(Equal(filepath.Join("/home/monkey", ".monkey_history")))
+	})
+
+	It("history file can be set or disabled", func() {
+		GinkgoT().Setenv("HISTORY_FILE", "/tmp/history")
+
+		config, err := setting.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.HistoryFile).To(Equal("/tmp/history"))
+
+		GinkgoT().Setenv("HISTORY_FILE", "")
+
+		config, err = setting.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.HistoryFile).To(BeEmpty())
+	})
 })
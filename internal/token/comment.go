@@ -0,0 +1,18 @@
+package token
+
+import "strings"
+
+// Comment is a comment in the source text, comments are kept as trivia of the token that follows them
+type Comment struct {
+	// the comment including its markers, e.g. "// note", "# note" or "/* note */"
+	Text string
+	// the position of the first character of the comment
+	Pos Position
+	// the position immediately after the comment
+	End Position
+}
+
+// IsBlock reports whether the comment is a /* */ block comment
+func (c Comment) IsBlock() bool {
+	return strings.HasPrefix(c.Text, "/*")
+}
@@ -0,0 +1,26 @@
+package token
+
+import "fmt"
+
+// Position is a location in the source text
+type Position struct {
+	// byte offset, starting at 0
+	Offset int
+	// line number, starting at 1
+	Line int
+	// column number in bytes, starting at 1
+	Column int
+}
+
+// IsValid reports whether the position points into the source text, the zero value does not
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
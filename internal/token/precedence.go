@@ -3,11 +3,19 @@ package token
 // precedence levels
 const (
 	LOWEST      = iota
+	ASSIGNMENT  // x = y, x += y
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
 	LESSGREATER // >, >=, <, <=
+	BITWISE_OR  // |
+	BITWISE_XOR // ^
+	BITWISE_AND // &
+	SHIFT       // <<, >>
 	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -x or !x
+	PRODUCT     // *, /, %
+	PREFIX      // -x, !x or ~x
+	POWER_OF    // x ** y
 	CALL        // fn(x) { return x + 1; } (1);
 	INDEX       // array[index]
 )
@@ -15,19 +23,34 @@ const (
 // token type to precedence maping
 var (
 	precedences = map[TokenType]int{
-		EQ:       EQUALS,
-		NOT_EQ:   EQUALS,
-		LT:       LESSGREATER,
-		LTE:      LESSGREATER,
-		GT:       LESSGREATER,
-		GTE:      LESSGREATER,
-		PLUS:     SUM,
-		MINUS:    SUM,
-		SLASH:    PRODUCT,
-		ASTERISK: PRODUCT,
-		BANG:     PREFIX,
-		LPAREN:   CALL,
-		LBRACKET: INDEX,
+		ASSIGN:          ASSIGNMENT,
+		PLUS_ASSIGN:     ASSIGNMENT,
+		MINUS_ASSIGN:    ASSIGNMENT,
+		ASTERISK_ASSIGN: ASSIGNMENT,
+		SLASH_ASSIGN:    ASSIGNMENT,
+		PERCENT_ASSIGN:  ASSIGNMENT,
+		OR:              LOGICAL_OR,
+		AND:             LOGICAL_AND,
+		EQ:              EQUALS,
+		NOT_EQ:          EQUALS,
+		LT:              LESSGREATER,
+		LTE:             LESSGREATER,
+		GT:              LESSGREATER,
+		GTE:             LESSGREATER,
+		PIPE:            BITWISE_OR,
+		CARET:           BITWISE_XOR,
+		AMPERSAND:       BITWISE_AND,
+		SHL:             SHIFT,
+		SHR:             SHIFT,
+		PLUS:            SUM,
+		MINUS:           SUM,
+		SLASH:           PRODUCT,
+		ASTERISK:        PRODUCT,
+		PERCENT:         PRODUCT,
+		POWER:           POWER_OF,
+		BANG:            PREFIX,
+		LPAREN:          CALL,
+		LBRACKET:        INDEX,
 	}
 )
 
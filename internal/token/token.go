@@ -1,6 +1,9 @@
 package token
 
-import "strconv"
+import (
+	"sort"
+	"strings"
+)
 
 const (
 	ILLEGAL = "ILLEGAL"
@@ -9,21 +12,42 @@ const (
 	// identifiers + literals
 	IDENT  = "IDENT" // add, foobar, x, y, ...
 	INT    = "INT"   // 123456
+	FLOAT  = "FLOAT" // 3.14, 1e-3
 	STRING = "STRING"
 
+	// parts of a string with interpolated expressions, e.g. "a ${b} c ${d} e"
+	STRING_HEAD   = "STRING_HEAD"   // "a ${
+	STRING_MIDDLE = "STRING_MIDDLE" // } c ${
+	STRING_TAIL   = "STRING_TAIL"   // } e"
+
 	// operators
-	ASSIGN   = "="
-	PLUS     = "+"
-	MINUS    = "-"
-	BANG     = "!"
-	ASTERISK = "*"
-	SLASH    = "/"
-	LT       = "<"
-	LTE      = "<="
-	GT       = ">"
-	GTE      = ">="
-	EQ       = "=="
-	NOT_EQ   = "!="
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
+	PLUS            = "+"
+	MINUS           = "-"
+	BANG            = "!"
+	ASTERISK        = "*"
+	SLASH           = "/"
+	PERCENT         = "%"
+	POWER           = "**"
+	AMPERSAND       = "&"
+	PIPE            = "|"
+	CARET           = "^"
+	TILDE           = "~"
+	SHL             = "<<"
+	SHR             = ">>"
+	LT              = "<"
+	LTE             = "<="
+	GT              = ">"
+	GTE             = ">="
+	EQ              = "=="
+	NOT_EQ          = "!="
+	AND             = "&&"
+	OR              = "||"
 
 	// delimiters
 	COMMA     = ","
@@ -37,38 +61,73 @@ const (
 	RBRACKET  = "]"
 
 	// keywords
-	FUNC   = "FUNC"
-	LET    = "LET"
-	TRUE   = "TRUE"
-	FALSE  = "FALSE"
-	IF     = "IF"
-	ELSE   = "ELSE"
-	RETURN = "RETURN"
+	FUNC     = "FUNC"
+	LET      = "LET"
+	TRUE     = "TRUE"
+	FALSE    = "FALSE"
+	NIL      = "NIL"
+	IF       = "IF"
+	ELSE     = "ELSE"
+	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
 )
 
 var keywordTable = map[string]TokenType{
-	"fn":     FUNC,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNC,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"nil":      NIL,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"throw":    THROW,
 }
 
 var operatorTable = map[string]TokenType{
 	"=":  ASSIGN,
+	"+=": PLUS_ASSIGN,
+	"-=": MINUS_ASSIGN,
+	"*=": ASTERISK_ASSIGN,
+	"/=": SLASH_ASSIGN,
+	"%=": PERCENT_ASSIGN,
 	"+":  PLUS,
 	"-":  MINUS,
 	"!":  BANG,
 	"*":  ASTERISK,
 	"/":  SLASH,
+	"%":  PERCENT,
+	"**": POWER,
+	"&":  AMPERSAND,
+	"|":  PIPE,
+	"^":  CARET,
+	"~":  TILDE,
+	"<<": SHL,
+	">>": SHR,
 	"<":  LT,
 	"<=": LTE,
 	">":  GT,
 	">=": GTE,
 	"==": EQ,
 	"!=": NOT_EQ,
+	"&&": AND,
+	"||": OR,
 }
 
 var delimeterTable = map[string]TokenType{
@@ -88,6 +147,12 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	// the position of the first character of the token
+	Pos Position
+	// the position immediately after the token
+	End Position
+	// the comments between the previous token and this one
+	Comments []Comment
 }
 
 func New(tokenType TokenType, literal string) Token {
@@ -97,6 +162,18 @@ func New(tokenType TokenType, literal string) Token {
 	}
 }
 
+// Keywords returns the reserved words of the language in sorted order
+func Keywords() []string {
+	keywords := make([]string, 0, len(keywordTable))
+	for keyword := range keywordTable {
+		keywords = append(keywords, keyword)
+	}
+
+	sort.Strings(keywords)
+
+	return keywords
+}
+
 func LookupTokenType(literal string) TokenType {
 	if tokType, ok := keywordTable[literal]; ok {
 		return tokType
@@ -110,7 +187,8 @@ func LookupTokenType(literal string) TokenType {
 		return tokType
 	}
 
-	if _, err := strconv.Atoi(literal); err == nil {
+	// integer literals may overflow int64, so only the digits are checked
+	if literal != "" && strings.Trim(literal, "0123456789") == "" {
 		return INT
 	}
 
@@ -0,0 +1,23 @@
+package vm
+
+import (
+	"errors"
+
+	"github.com/aden-q/monkey/internal/compiler"
+	"github.com/aden-q/monkey/internal/object"
+)
+
+var (
+	ErrStackOverflow          = object.ErrStackOverflow
+	ErrUnexpectedObjectType   = errors.New("unexpected object type")
+	ErrUnexpectedOperatorType = object.ErrUnexpectedOperatorType
+	ErrIdentifierNotFound     = errors.New("identifier not found")
+	ErrNotAFunction           = errors.New("not a function")
+	ErrWrongNumberArguments   = errors.New("wrong number of argument(s)")
+	ErrIndexOutOfRange        = errors.New("index out of range")
+	ErrUnhashableType         = errors.New("unhashable type")
+	ErrKeyNotFound            = errors.New("key not found")
+	ErrNotIterable            = object.ErrNotIterable
+	ErrDivisionByZero         = object.ErrDivisionByZero
+	ErrInvalidBytecode        = compiler.ErrInvalidBytecode
+)
@@ -0,0 +1,27 @@
+package vm
+
+import (
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/object"
+)
+
+// frame holds the execution state of a single function call
+type frame struct {
+	cl *object.Closure
+	// instruction pointer, the offset of the instruction being executed
+	ip int
+	// stack pointer before the call, locals are stored starting from here
+	basePointer int
+}
+
+func newFrame(cl *object.Closure, basePointer int) *frame {
+	return &frame{
+		cl:          cl,
+		ip:          -1,
+		basePointer: basePointer,
+	}
+}
+
+func (f *frame) instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
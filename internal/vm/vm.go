@@ -0,0 +1,711 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/compiler"
+	"github.com/aden-q/monkey/internal/object"
+)
+
+const (
+	// the operand stack holds the locals and operands of every frame, it is large enough
+	// for calls nested up to the maximum depth unless their frames are unusually large
+	StackSize   = 1 << 17
+	GlobalsSize = 65536
+	// the main frame and one frame per call in progress
+	MaxFrames = object.MaxCallDepth + 1
+)
+
+// interface compliance check
+var _ VM = (*vm)(nil)
+
+// A stack based virtual machine interface
+type VM interface {
+	Run() (object.Object, error)
+}
+
+type vm struct {
+	constants []object.Object
+	globals   []object.Object
+
+	// the operand stack, sp always points to the next free slot
+	stack []object.Object
+	sp    int
+	// the last value popped off the stack, it is the result of the program
+	lastPopped object.Object
+
+	frames      []*frame
+	framesIndex int
+
+	// where builtins such as print write their output
+	out io.Writer
+}
+
+func New(bytecode *compiler.Bytecode) VM {
+	return NewWithGlobals(bytecode, NewGlobals())
+}
+
+// NewWithGlobals creates a VM that keeps using the given globals store,
+// this allows globals to survive between runs (e.g. in a REPL)
+func NewWithGlobals(bytecode *compiler.Bytecode, globals []object.Object) VM {
+	return NewWithOutput(bytecode, globals, os.Stdout)
+}
+
+// NewWithOutput creates a VM like NewWithGlobals whose builtins such as print write to out
+func NewWithOutput(bytecode *compiler.Bytecode, globals []object.Object, out io.Writer) VM {
+	mainFn := object.NewCompiledFunc(bytecode.Instructions, 0, 0)
+	mainFrame := newFrame(object.NewClosure(mainFn, nil), 0)
+
+	frames := make([]*frame, MaxFrames)
+	frames[0] = mainFrame
+
+	return &vm{
+		constants:   bytecode.Constants,
+		globals:     globals,
+		stack:       make([]object.Object, StackSize),
+		sp:          0,
+		frames:      frames,
+		framesIndex: 1,
+		out:         out,
+	}
+}
+
+// NewGlobals allocates a globals store, unset slots are nil
+func NewGlobals() []object.Object {
+	return make([]object.Object, GlobalsSize)
+}
+
+// Run executes the bytecode and returns the value of the program. Decoding checks the operands of
+// every instruction, but bytecode the compiler did not produce can still fail at runtime, e.g. by
+// popping an empty stack, which is reported as ErrInvalidBytecode
+func (v *vm) Run() (result object.Object, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = object.NIL, fmt.Errorf("%w: %v", ErrInvalidBytecode, r)
+		}
+	}()
+
+	returnValue, err := v.run(0)
+	if err != nil {
+		return object.NIL, err
+	}
+
+	if returnValue != nil {
+		return returnValue, nil
+	}
+
+	if v.lastPopped == nil {
+		return object.NIL, nil
+	}
+
+	return v.lastPopped, nil
+}
+
+// run executes instructions as long as there are more than depth frames, it returns the value
+// of a return statement outside of any function, nil when the instructions run out
+func (v *vm) run(depth int) (object.Object, error) {
+	for v.framesIndex > depth && v.currentFrame().ip < len(v.currentFrame().instructions())-1 {
+		v.currentFrame().ip++
+
+		ip := v.currentFrame().ip
+		ins := v.currentFrame().instructions()
+		op := code.Opcode(ins[ip])
+
+		var err error
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			v.currentFrame().ip += 2
+
+			err = v.push(v.constants[constIndex])
+		case code.OpPop:
+			v.lastPopped = v.pop()
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod, code.OpPow,
+			code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpShiftLeft, code.OpShiftRight,
+			code.OpEqual, code.OpNotEqual,
+			code.OpLessThan, code.OpLessThanEqual, code.OpGreaterThan, code.OpGreaterThanEqual:
+			err = v.executeBinaryOperation(op)
+		case code.OpTrue:
+			err = v.push(object.TRUE)
+		case code.OpFalse:
+			err = v.push(object.FALSE)
+		case code.OpNil:
+			err = v.push(object.NIL)
+		case code.OpMinus:
+			err = v.executeMinusOperator()
+		case code.OpBang:
+			err = v.executeBangOperator()
+		case code.OpBitNot:
+			err = v.executeBitNotOperator()
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			// the loop increments ip before fetching the next instruction
+			v.currentFrame().ip = pos - 1
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			if condition := v.pop(); !condition.IsTruthy() {
+				v.currentFrame().ip = pos - 1
+			}
+		case code.OpJumpNotTruthyOrPop, code.OpJumpTruthyOrPop:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			if v.stack[v.sp-1].IsTruthy() == (op == code.OpJumpTruthyOrPop) {
+				v.currentFrame().ip = pos - 1
+			} else {
+				v.pop()
+			}
+		case code.OpIter:
+			elements, ok := object.Iterate(v.pop())
+			if !ok {
+				return object.NIL, ErrNotIterable
+			}
+
+			err = v.push(object.NewArray(elements...))
+			if err == nil {
+				err = v.push(object.NewInteger(0))
+			}
+		case code.OpIterNext:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			index := v.pop().(*object.Integer).Value
+			elements := v.pop().(*object.Array).Elements
+
+			if index >= int64(len(elements)) {
+				v.currentFrame().ip = pos - 1
+				break
+			}
+
+			err = v.push(elements[index])
+			if err == nil {
+				err = v.push(object.NewInteger(index + 1))
+			}
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			v.currentFrame().ip += 2
+
+			v.globals[globalIndex] = v.pop()
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			v.currentFrame().ip += 2
+
+			val := v.globals[globalIndex]
+			if val == nil {
+				return object.NIL, ErrIdentifierNotFound
+			}
+
+			err = v.push(val)
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			v.currentFrame().ip += 1
+
+			v.stack[v.currentFrame().basePointer+int(localIndex)] = v.pop()
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			v.currentFrame().ip += 1
+
+			err = v.push(v.stack[v.currentFrame().basePointer+int(localIndex)])
+		case code.OpGetBuiltin:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			v.currentFrame().ip += 2
+
+			name := v.constants[constIndex].(*object.String).Value
+			builtinFunc, ok := object.BuiltinFuncs[name]
+			if !ok {
+				return object.NIL, ErrIdentifierNotFound
+			}
+
+			err = v.push(builtinFunc)
+		case code.OpGetFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			v.currentFrame().ip += 1
+
+			err = v.push(v.currentFrame().cl.Free[freeIndex])
+		case code.OpCurrentClosure:
+			err = v.push(v.currentFrame().cl)
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			elements := make([]object.Object, numElements)
+			copy(elements, v.stack[v.sp-numElements:v.sp])
+			v.sp -= numElements
+
+			err = v.push(object.NewArray(elements...))
+		case code.OpHash:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			var hash object.Object
+			hash, err = v.buildHash(v.sp-numElements, v.sp)
+			if err != nil {
+				return object.NIL, err
+			}
+
+			v.sp -= numElements
+			err = v.push(hash)
+		case code.OpSetIndex:
+			value := v.pop()
+			index := v.pop()
+
+			err = v.executeIndexAssignment(v.pop(), index, func(object.Object) (object.Object, error) {
+				return value, nil
+			})
+		case code.OpUpdateIndex:
+			infixOp := code.Opcode(code.ReadUint8(ins[ip+1:]))
+			v.currentFrame().ip += 1
+
+			value := v.pop()
+			index := v.pop()
+
+			err = v.executeIndexAssignment(v.pop(), index, func(current object.Object) (object.Object, error) {
+				// a compound assignment needs an existing hash entry
+				if current == nil {
+					return object.NIL, ErrKeyNotFound
+				}
+
+				return binaryOperation(infixOp, current, value)
+			})
+		case code.OpSlice:
+			bounds := int(code.ReadUint8(ins[ip+1:]))
+			v.currentFrame().ip += 1
+
+			err = v.executeSliceExpression(bounds)
+		case code.OpInterpolate:
+			numParts := int(code.ReadUint16(ins[ip+1:]))
+			v.currentFrame().ip += 2
+
+			builder := strings.Builder{}
+			for _, part := range v.stack[v.sp-numParts : v.sp] {
+				builder.WriteString(part.Inspect())
+			}
+
+			v.sp -= numParts
+			err = v.push(object.NewString(builder.String()))
+		case code.OpIndex:
+			index := v.pop()
+			left := v.pop()
+
+			err = v.executeIndexExpression(left, index)
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := code.ReadUint8(ins[ip+3:])
+			v.currentFrame().ip += 3
+
+			err = v.pushClosure(int(constIndex), int(numFree))
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			v.currentFrame().ip += 1
+
+			err = v.executeCall(int(numArgs))
+		case code.OpReturnValue:
+			returnValue := v.pop()
+
+			// a return statement outside of any function terminates the program
+			if v.framesIndex == 1 {
+				return object.NewReturnValue(returnValue), nil
+			}
+
+			f := v.popFrame()
+			// drop the locals and the function itself
+			v.sp = f.basePointer - 1
+
+			err = v.push(returnValue)
+		}
+
+		if err != nil {
+			return object.NIL, err
+		}
+	}
+
+	return nil, nil
+}
+
+// binaryOperators maps the opcodes of infix operators to the operators shared with the evaluator
+var binaryOperators = map[code.Opcode]string{
+	code.OpAdd:              "+",
+	code.OpSub:              "-",
+	code.OpMul:              "*",
+	code.OpDiv:              "/",
+	code.OpMod:              "%",
+	code.OpPow:              "**",
+	code.OpBitAnd:           "&",
+	code.OpBitOr:            "|",
+	code.OpBitXor:           "^",
+	code.OpShiftLeft:        "<<",
+	code.OpShiftRight:       ">>",
+	code.OpEqual:            "==",
+	code.OpNotEqual:         "!=",
+	code.OpLessThan:         "<",
+	code.OpLessThanEqual:    "<=",
+	code.OpGreaterThan:      ">",
+	code.OpGreaterThanEqual: ">=",
+}
+
+// executeBinaryOperation evaluates an infix operator applied to the two topmost objects on the stack
+func (v *vm) executeBinaryOperation(op code.Opcode) error {
+	right := v.pop()
+	left := v.pop()
+
+	result, err := binaryOperation(op, left, right)
+	if err != nil {
+		return err
+	}
+
+	return v.push(result)
+}
+
+// binaryOperation applies the infix operator of an opcode to two objects
+func binaryOperation(op code.Opcode, left, right object.Object) (object.Object, error) {
+	switch {
+	case object.IsNumber(left) && object.IsNumber(right):
+		return object.NumberInfix(binaryOperators[op], left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return stringBinaryOperation(op, left.(*object.String), right.(*object.String))
+	// equality test
+	case op == code.OpEqual:
+		return booleanConv(reflect.DeepEqual(left, right)), nil
+	case op == code.OpNotEqual:
+		return booleanConv(!reflect.DeepEqual(left, right)), nil
+	default:
+		return object.NIL, ErrUnexpectedObjectType
+	}
+}
+
+// stringBinaryOperation applies an infix operator to two string operands
+func stringBinaryOperation(op code.Opcode, left, right *object.String) (object.Object, error) {
+	switch op {
+	case code.OpAdd:
+		return object.NewString(left.Value + right.Value), nil
+	}
+
+	return object.NIL, ErrUnexpectedOperatorType
+}
+
+func (v *vm) executeMinusOperator() error {
+	negated, ok := object.Negate(v.pop())
+	if !ok {
+		return ErrUnexpectedObjectType
+	}
+
+	return v.push(negated)
+}
+
+// executeBitNotOperator flips all the bits of an integer
+func (v *vm) executeBitNotOperator() error {
+	flipped, ok := object.BitNot(v.pop())
+	if !ok {
+		return ErrUnexpectedObjectType
+	}
+
+	return v.push(flipped)
+}
+
+// executeBangOperator negates the truthiness of the operand, the same truthiness used by conditions
+func (v *vm) executeBangOperator() error {
+	return v.push(booleanConv(!v.pop().IsTruthy()))
+}
+
+func (v *vm) executeIndexExpression(left, index object.Object) error {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		integer := index.(*object.Integer)
+		idx := integer.Value
+		maxIdx := int64(len(array.Elements) - 1)
+
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
+			return ErrIndexOutOfRange
+		}
+
+		return v.push(array.Elements[idx])
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		// strings are indexed by character, not by byte
+		runes := []rune(left.(*object.String).Value)
+		integer := index.(*object.Integer)
+		idx := integer.Value
+		maxIdx := int64(len(runes) - 1)
+
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
+			return ErrIndexOutOfRange
+		}
+
+		return v.push(object.NewString(string(runes[idx])))
+	case left.Type() == object.HASH_OBJ:
+		hash := left.(*object.Hash)
+
+		// a key must be hashable in order to be used as a key in a hash object
+		hashKey, ok := index.(object.Hashable)
+		if !ok {
+			return ErrUnhashableType
+		}
+
+		if val, ok := hash.Items[hashKey.HashKey()]; ok {
+			return v.push(val)
+		}
+
+		return ErrKeyNotFound
+	default:
+		return ErrUnexpectedObjectType
+	}
+}
+
+// executeIndexAssignment assigns to an array element or a hash entry and pushes the assigned value,
+// update computes the value from the current one, which is nil for a hash entry that does not exist yet
+func (v *vm) executeIndexAssignment(left, index object.Object, update func(current object.Object) (object.Object, error)) error {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		integer := index.(*object.Integer)
+		idx := integer.Value
+		maxIdx := int64(len(array.Elements) - 1)
+
+		if integer.IsBig() || idx < 0 || idx > maxIdx {
+			return ErrIndexOutOfRange
+		}
+
+		val, err := update(array.Elements[idx])
+		if err != nil {
+			return err
+		}
+
+		array.Elements[idx] = val
+
+		return v.push(val)
+	case left.Type() == object.HASH_OBJ:
+		hash := left.(*object.Hash)
+
+		// a key must be hashable in order to be used as a key in a hash object
+		hashKey, ok := index.(object.Hashable)
+		if !ok {
+			return ErrUnhashableType
+		}
+
+		val, err := update(hash.Items[hashKey.HashKey()])
+		if err != nil {
+			return err
+		}
+
+		hash.Items[hashKey.HashKey()] = val
+
+		return v.push(val)
+	default:
+		return ErrUnexpectedObjectType
+	}
+}
+
+// executeSliceExpression slices an array or a string, the indexes that were not omitted are on top
+// of the sliced object, negative indexes count from the end and out of range indexes are clamped
+func (v *vm) executeSliceExpression(bounds int) error {
+	var low, high object.Object
+
+	if bounds&code.SliceHigh != 0 {
+		high = v.pop()
+	}
+
+	if bounds&code.SliceLow != 0 {
+		low = v.pop()
+	}
+
+	left := v.pop()
+
+	var length int64
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = int64(len(left.Elements))
+	case *object.String:
+		length = int64(utf8.RuneCountInString(left.Value))
+	default:
+		return ErrUnexpectedObjectType
+	}
+
+	lowVal, err := sliceIndex(low, 0)
+	if err != nil {
+		return err
+	}
+
+	highVal, err := sliceIndex(high, length)
+	if err != nil {
+		return err
+	}
+
+	if array, ok := left.(*object.Array); ok {
+		return v.push(array.Slice(lowVal, highVal))
+	}
+
+	return v.push(left.(*object.String).Slice(lowVal, highVal))
+}
+
+// sliceIndex converts a slice index, which is value when it is omitted
+func sliceIndex(index object.Object, value int64) (int64, error) {
+	if index == nil {
+		return value, nil
+	}
+
+	integer, ok := index.(*object.Integer)
+	if !ok {
+		return 0, ErrUnexpectedObjectType
+	}
+
+	if integer.IsBig() {
+		return 0, ErrIndexOutOfRange
+	}
+
+	return integer.Value, nil
+}
+
+// buildHash creates a hash object from the key value pairs stored in stack[start:end]
+func (v *vm) buildHash(start, end int) (object.Object, error) {
+	items := make(map[object.HashKey]object.Object, (end-start)/2)
+
+	for i := start; i < end; i += 2 {
+		key := v.stack[i]
+		value := v.stack[i+1]
+
+		// a key must be hashable in order to be used as a key in a hash object
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return object.NIL, ErrUnhashableType
+		}
+
+		items[hashKey.HashKey()] = value
+	}
+
+	return object.NewHash(items), nil
+}
+
+func (v *vm) pushClosure(constIndex, numFree int) error {
+	fn, ok := v.constants[constIndex].(*object.CompiledFunc)
+	if !ok {
+		return ErrNotAFunction
+	}
+
+	free := make([]object.Object, numFree)
+	copy(free, v.stack[v.sp-numFree:v.sp])
+	v.sp -= numFree
+
+	return v.push(object.NewClosure(fn, free))
+}
+
+// executeCall calls the object sitting below the arguments on the stack
+func (v *vm) executeCall(numArgs int) error {
+	switch callee := v.stack[v.sp-1-numArgs].(type) {
+	case *object.Closure:
+		return v.callClosure(callee, numArgs)
+	case object.BuiltinFunc:
+		return v.callBuiltin(callee, numArgs)
+	default:
+		return ErrNotAFunction
+	}
+}
+
+func (v *vm) callClosure(cl *object.Closure, numArgs int) error {
+	if numArgs != cl.Fn.NumParameters {
+		return ErrWrongNumberArguments
+	}
+
+	if v.framesIndex >= MaxFrames || v.sp-numArgs+cl.Fn.NumLocals >= StackSize {
+		return ErrStackOverflow
+	}
+
+	// arguments are already on the stack, they become the first locals of the new frame
+	f := newFrame(cl, v.sp-numArgs)
+	v.pushFrame(f)
+	v.sp = f.basePointer + cl.Fn.NumLocals
+
+	return nil
+}
+
+func (v *vm) callBuiltin(fn object.BuiltinFunc, numArgs int) error {
+	args := make([]object.Object, numArgs)
+	copy(args, v.stack[v.sp-numArgs:v.sp])
+
+	result, err := fn(&object.CallContext{Out: v.out, Call: v.callFunction}, args...)
+	if err != nil {
+		return err
+	}
+
+	// drop the arguments and the function itself
+	v.sp = v.sp - numArgs - 1
+
+	return v.push(result)
+}
+
+// callFunction calls a function object on behalf of a builtin function, a closure is run until it returns
+func (v *vm) callFunction(fn object.Object, args ...object.Object) (object.Object, error) {
+	depth := v.framesIndex
+
+	if err := v.push(fn); err != nil {
+		return object.NIL, err
+	}
+
+	for _, arg := range args {
+		if err := v.push(arg); err != nil {
+			return object.NIL, err
+		}
+	}
+
+	if err := v.executeCall(len(args)); err != nil {
+		return object.NIL, err
+	}
+
+	if _, err := v.run(depth); err != nil {
+		return object.NIL, err
+	}
+
+	// both closures and builtins leave their result on the stack
+	return v.pop(), nil
+}
+
+func (v *vm) push(obj object.Object) error {
+	if v.sp >= StackSize {
+		return ErrStackOverflow
+	}
+
+	v.stack[v.sp] = obj
+	v.sp++
+
+	return nil
+}
+
+func (v *vm) pop() object.Object {
+	obj := v.stack[v.sp-1]
+	v.sp--
+
+	return obj
+}
+
+func (v *vm) currentFrame() *frame {
+	return v.frames[v.framesIndex-1]
+}
+
+func (v *vm) pushFrame(f *frame) {
+	v.frames[v.framesIndex] = f
+	v.framesIndex++
+}
+
+func (v *vm) popFrame() *frame {
+	v.framesIndex--
+
+	return v.frames[v.framesIndex]
+}
+
+// booleanConv converts a boolean literal to a boolean object in the object system
+func booleanConv(input bool) object.Object {
+	if input {
+		return object.TRUE
+	}
+
+	return object.FALSE
+}
@@ -0,0 +1,283 @@
+package vm_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/aden-q/monkey/internal/code"
+	"github.com/aden-q/monkey/internal/compiler"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+	"github.com/aden-q/monkey/internal/vm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VM", func() {
+	var (
+		p parser.Parser
+	)
+
+	BeforeEach(func() {
+		p = parser.New(lexer.New())
+	})
+
+	// run parses, compiles and executes a program
+	run := func(text string) (object.Object, error) {
+		program, errs := p.ParseProgram(text)
+		Expect(errs).To(BeEmpty())
+
+		c := compiler.New()
+		Expect(c.Compile(program)).To(Succeed())
+
+		return vm.New(c.Bytecode()).Run()
+	}
+
+	// bigInt creates an integer object from a decimal literal
+	bigInt := func(text string) *object.Integer {
+		value, ok := new(big.Int).SetString(text, 10)
+		Expect(ok).To(BeTrue())
+
+		return object.NewBigInteger(value)
+	}
+
+	Describe("Run", func() {
+		// the same programs are covered by the evaluator tests, both engines must agree on the results
+		DescribeTable("evaluator parity",
+			func(text string, expectedObject object.Object) {
+				obj, err := run(text)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obj).To(Equal(expectedObject))
+			},
+			Entry("integer expression", `5;`, object.NewInteger(5)),
+			Entry("prefix minus operator expression", `-5;`, object.NewInteger(-5)),
+			Entry("infix plus operator expression", `5 + 5;`, object.NewInteger(10)),
+			Entry("infix minus operator expression", `5 - 5;`, object.NewInteger(0)),
+			Entry("infix multiply operator expression", `5 * 5;`, object.NewInteger(25)),
+			Entry("infix division operator expression", `10 / 5;`, object.NewInteger(2)),
+			Entry("infix mix-operator expression", `5 + 5 - 2 + 10 * 3 / 5;`, object.NewInteger(14)),
+			Entry("boolean expression", `true;`, object.TRUE),
+			Entry("prefix bang boolean expression", `!true;`, object.FALSE),
+			Entry("prefix bang boolean expression", `!false;`, object.TRUE),
+			Entry("prefix bang boolean expression", `!5;`, object.FALSE),
+			Entry("prefix bang boolean expression", `!!true;`, object.TRUE),
+			Entry("prefix bang boolean expression", `!!false;`, object.FALSE),
+			Entry("prefix bang boolean expression", `!!5;`, object.TRUE),
+			Entry("infix comparision expression", `1 < 2;`, object.TRUE),
+			Entry("infix comparision expression", `1 <= 2;`, object.TRUE),
+			Entry("infix comparision expression", `2 < 1;`, object.FALSE),
+			Entry("infix comparision expression", `2 <= 1;`, object.FALSE),
+			Entry("infix comparision expression", `1 > 2;`, object.FALSE),
+			Entry("infix comparision expression", `1 >= 2;`, object.FALSE),
+			Entry("infix comparision expression", `2 > 1;`, object.TRUE),
+			Entry("infix comparision expression", `2 >= 1;`, object.TRUE),
+			Entry("infix comparision expression", `1 == 1;`, object.TRUE),
+			Entry("infix comparision expression", `2 == 1;`, object.FALSE),
+			Entry("infix comparision expression", `2 != 1;`, object.TRUE),
+			Entry("infix comparision expression", `1 != 1;`, object.FALSE),
+			Entry("infix comparision expression", `true == true;`, object.TRUE),
+			Entry("infix comparision expression", `false == false;`, object.TRUE),
+			Entry("infix comparision expression", `true == false;`, object.FALSE),
+			Entry("infix comparision expression", `true != true;`, object.FALSE),
+			Entry("infix comparision expression", `true != false;`, object.TRUE),
+			Entry("string expression", `"hello world";`, object.NewString("hello world")),
+			Entry("infix string concatenation", `"hello" + " " + "world!";`, object.NewString("hello world!")),
+			Entry("array expression", `[1, 2 * 2, true];`, object.NewArray(object.NewInteger(1), object.NewInteger(4), object.TRUE)),
+			Entry("index expression", `[1, 2 * 2, true][0];`, object.NewInteger(1)),
+			Entry("index expression", `[1, 2 * 2, true][2];`, object.TRUE),
+			Entry("index expression", `let a = [1, 2 * 2, true]; a[1];`, object.NewInteger(4)),
+			Entry("hash expression", `{"foo": 5, "bar": 10 * 2, "foobar": true, 1: "hello", true: "world"};`, object.NewHash(map[object.HashKey]object.Object{
+				object.NewString("foo").HashKey():    object.NewInteger(5),
+				object.NewString("bar").HashKey():    object.NewInteger(20),
+				object.NewString("foobar").HashKey(): object.TRUE,
+				object.NewInteger(1).HashKey():       object.NewString("hello"),
+				object.TRUE.HashKey():                object.NewString("world"),
+			})),
+			Entry("index expression", `let a = {"foo": 5 + 5}; a["foo"];`, object.NewInteger(10)),
+			Entry("index expression, string", `"héllo"[1];`, object.NewString("é")),
+			Entry("if condition is truthy", `if (true) { 10; };`, object.NewInteger(10)),
+			Entry("if condition is truthy", `if (5) { 10; };`, object.NewInteger(10)),
+			Entry("if condition is truthy", `if (1 < 2) { 10; };`, object.NewInteger(10)),
+			Entry("if condition is false", `if (false) { 10; };`, object.NIL),
+			Entry("return an integer", `return 10;`, object.NewReturnValue(object.NewInteger(10))),
+			Entry("return an integer", `9; false; return 10; 5; true;`, object.NewReturnValue(object.NewInteger(10))),
+			Entry("return an integer with if condition", `if (10 > 1) { return 10; }; return 5;`, object.NewReturnValue(object.NewInteger(10))),
+			Entry("return an integer with nested if conditions", `if (10 > 1) { if (10 > 1) { return 10; }; return 8; }; return 5;`, object.NewReturnValue(object.NewInteger(10))),
+			Entry("return an boolean with if condition", `if (10 > 1) { return 10 > 1; }; return false;`, object.NewReturnValue(object.TRUE)),
+			Entry("successful binding", `let a = 5 * 5; a;`, object.NewInteger(25)),
+			Entry("func with 1 parameter", `let a = fn(x) { x + 2; }; a(5);`, object.NewInteger(7)),
+			Entry("func with 2 parameters", `let a = fn(x, y) { x * y + 2; }; a(5, 6);`, object.NewInteger(32)),
+			Entry("func with 2 parameters", `fn(x, y) { x * y + 2; } (5, 6);`, object.NewInteger(32)),
+			Entry("length of an empty string", `len("");`, object.NewInteger(0)),
+			Entry("length of a non-empty string", `len("hello world");`, object.NewInteger(11)),
+			Entry("length of an array", `len([1,2,true]);`, object.NewInteger(3)),
+			Entry("int64 overflow is promoted", `9223372036854775807 + 1;`, bigInt("9223372036854775808")),
+			Entry("big integer literal", `9223372036854775808 * 2;`, bigInt("18446744073709551616")),
+			Entry("big integer made at runtime", `int("18446744073709551617") + 1;`, bigInt("18446744073709551618")),
+			Entry("big integer comparison", `int("18446744073709551617") > 9223372036854775807;`, object.TRUE),
+			Entry("big integer demoted back", `int("18446744073709551617") - int("18446744073709551616");`, object.NewInteger(1)),
+			Entry("negating a big integer", `-int("9223372036854775808");`, object.NewInteger(math.MinInt64)),
+			Entry("negating the smallest int64", `-(-9223372036854775807 - 1);`, bigInt("9223372036854775808")),
+			Entry("float made at runtime", `float("1.5") * 2;`, object.NewFloat(3)),
+			Entry("float literal with an exponent", `2.5e-3;`, object.NewFloat(0.0025)),
+			Entry("mixing integers and floats promotes to float", `10 / 4.0 + 1;`, object.NewFloat(3.5)),
+			Entry("integers and floats compare by value", `[1 == 1.0, 2.5 > 2];`, object.NewArray(object.TRUE, object.TRUE)),
+			Entry("modulo and power", `[7 % 3, -7 % 3, 7.5 % 2, 2 ** 10, 2 ** -1, -2 ** 2];`, object.NewArray(object.NewInteger(1), object.NewInteger(-1), object.NewFloat(1.5), object.NewInteger(1024), object.NewFloat(0.5), object.NewInteger(-4))),
+			Entry("bitwise operators", `[6 & 3 | 8, 6 ^ 3, 1 << 4, -16 >> 2, ~5, ~int("9223372036854775808")];`, object.NewArray(object.NewInteger(10), object.NewInteger(5), object.NewInteger(16), object.NewInteger(-4), object.NewInteger(-6), bigInt("-9223372036854775809"))),
+			Entry("shifts overflowing int64 are promoted", `1 << 64;`, bigInt("18446744073709551616")),
+			Entry("interpolated string", `let name = "monkey"; "hello ${name}, ${1 + 1} ${[1, "a"]}!";`, object.NewString("hello monkey, 2 [1, a]!")),
+			Entry("interpolated string without text", `"${1}${true}";`, object.NewString("1true")),
+			Entry("array slices", `let a = [1, 2, 3, 4]; [a[1:3], a[:2], a[2:], a[:], a[-2:], a[3:1], a[-10:10]];`, object.NewArray(
+				object.NewArray(object.NewInteger(2), object.NewInteger(3)),
+				object.NewArray(object.NewInteger(1), object.NewInteger(2)),
+				object.NewArray(object.NewInteger(3), object.NewInteger(4)),
+				object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(3), object.NewInteger(4)),
+				object.NewArray(object.NewInteger(3), object.NewInteger(4)),
+				&object.Array{Elements: []object.Object{}},
+				object.NewArray(object.NewInteger(1), object.NewInteger(2), object.NewInteger(3), object.NewInteger(4)),
+			)),
+			Entry("string slices by character", `let s = "héllo"; [s[1:3], s[:-1], s[3:]];`, object.NewArray(object.NewString("él"), object.NewString("héll"), object.NewString("lo"))),
+			Entry("while loop", `let xs = []; while (len(xs) < 3) { let xs = push(xs, len(xs)); }; xs;`, object.NewArray(object.NewInteger(0), object.NewInteger(1), object.NewInteger(2))),
+			Entry("loops evaluate to nil", `let xs = [1]; for (x in xs) { x; };`, object.NIL),
+			Entry("for loop over an array", `let sum = 0; for (x in [1, 2, 3]) { let sum = sum + x; }; sum;`, object.NewInteger(6)),
+			Entry("for loop over a string", `let s = ""; for (ch in "héllo") { let s = ch + s; }; s;`, object.NewString("olléh")),
+			Entry("for loop over the keys of a hash", `let ks = []; for (k in {"b": 1, "a": 2}) { let ks = push(ks, k); }; ks;`, object.NewArray(object.NewString("a"), object.NewString("b"))),
+			Entry("break and continue", `let xs = []; for (x in [1, 2, 3, 4, 5, 6]) { if (x == 2) { continue; }; if (x == 5) { break; }; let xs = push(xs, x); }; xs;`, object.NewArray(object.NewInteger(1), object.NewInteger(3), object.NewInteger(4))),
+			Entry("nested loops", `let xs = []; for (i in [1, 2]) { for (j in [1, 2, 3]) { if (j > i) { break; }; let xs = push(xs, i * 10 + j); }; }; xs;`, object.NewArray(object.NewInteger(11), object.NewInteger(21), object.NewInteger(22))),
+			Entry("loops in functions", `let f = fn(n) { let total = 0; for (x in range(n)) { let total = total + x; }; total; }; [f(3), f(5)];`, object.NewArray(object.NewInteger(3), object.NewInteger(10))),
+			Entry("return from a loop", `let find = fn(xs, y) { for (x in xs) { if (x == y) { return true; }; }; false; }; [find([1, 2], 2), find([1, 2], 3)];`, object.NewArray(object.TRUE, object.FALSE)),
+			Entry("assignment", `let x = 1; x = x + 1; x += 3; x;`, object.NewInteger(5)),
+			Entry("assignment evaluates to the assigned value", `let x = 1; let y = (x = 7); [x, y];`, object.NewArray(object.NewInteger(7), object.NewInteger(7))),
+			Entry("assignment to a local", `let f = fn(n) { let m = 2; m *= n; n -= 1; [m, n]; }; f(5);`, object.NewArray(object.NewInteger(10), object.NewInteger(4))),
+			Entry("assignment to a global from a function", `let count = 0; let inc = fn() { count += 1; }; inc(); inc(); count;`, object.NewInteger(2)),
+			Entry("compound assignment on strings", `let s = "a"; s += "b"; s;`, object.NewString("ab")),
+			Entry("compound assignment promotes to float", `let x = 1; x /= 2.0; x;`, object.NewFloat(0.5)),
+			Entry("assignment in a loop", `let i = 0; while (i < 5) { i += 1; }; i;`, object.NewInteger(5)),
+			Entry("array element assignment", `let a = [1, 2]; a[0] = 5; a[1] += 10; a;`, object.NewArray(object.NewInteger(5), object.NewInteger(12))),
+			Entry("hash entry assignment", `let h = {"a": 1}; h["b"] = 2; h["a"] -= 1; [h["a"], h["b"]];`, object.NewArray(object.NewInteger(0), object.NewInteger(2))),
+			Entry("logical operators evaluate to the deciding operand", `[1 && 2, 0 && 2, 0 || "a", 1 || 2, false || false];`, object.NewArray(object.NewInteger(2), object.NewInteger(0), object.NewString("a"), object.NewInteger(1), object.FALSE)),
+			Entry("nil literal", `let check = fn(x) { x != nil && x[0] > 1; }; [nil, check([2]), check(nil)];`, object.NewArray(object.NIL, object.TRUE, object.FALSE)),
+			Entry("functions are truthy", `let f = fn(x) { x; }; let g = f || fn(x) { 0; }; [!f, !len, g(5), if (f) { 1; } else { 2; }];`, object.NewArray(object.FALSE, object.FALSE, object.NewInteger(5), object.NewInteger(1))),
+			Entry("logical operators short-circuit", `let f = fn() { 1 / 0; }; [false && f(), true || f()];`, object.NewArray(object.FALSE, object.TRUE)),
+			Entry("whole-number float keys find integer keys", `{1: "a"}[1.0];`, object.NewString("a")),
+			Entry("bang on falsy values", `[!false, !0, !"", ![], !{}, !if (false) { 1; }];`, object.NewArray(object.TRUE, object.TRUE, object.TRUE, object.TRUE, object.TRUE, object.TRUE)),
+			Entry("bang on truthy values", `[!true, !1, !"a", ![0], !{"a": 1}, !!0];`, object.NewArray(object.FALSE, object.FALSE, object.FALSE, object.FALSE, object.FALSE, object.FALSE)),
+		)
+
+		DescribeTable("evaluator parity on errors",
+			func(text string, expectedError error) {
+				obj, err := run(text)
+				Expect(err).To(MatchError(expectedError))
+				Expect(obj).To(Equal(object.NIL))
+			},
+			Entry("index expression, index out of range", `let a = [1, 2 * 2, true]; a[3];`, vm.ErrIndexOutOfRange),
+			Entry("index expression, key not found", `let a = {"foo": 5}; a["bar"];`, vm.ErrKeyNotFound),
+			Entry("index expression, string index out of range", `"abc"[3];`, vm.ErrIndexOutOfRange),
+			Entry("can detect errors", `5 + true;`, vm.ErrUnexpectedObjectType),
+			Entry("can early terminate when there's an error", `5 + true; 10;`, vm.ErrUnexpectedObjectType),
+			Entry("unbound identifier", `foobar;`, vm.ErrIdentifierNotFound),
+			Entry("length on integer unsupported", `len(1);`, object.ErrUnsupportedArgumentType),
+			Entry("division by zero", `let zero = 0; print(1 / zero);`, vm.ErrDivisionByZero),
+			Entry("float division by zero", `1.5 / 0;`, vm.ErrDivisionByZero),
+			Entry("modulo by zero", `7 % 0;`, vm.ErrDivisionByZero),
+			Entry("negative shift count", `1 << -1;`, object.ErrInvalidShiftCount),
+			Entry("power too large", `2 ** 10000000000;`, object.ErrIntegerTooLarge),
+			Entry("bitwise operators on floats", `1.5 & 1;`, vm.ErrUnexpectedOperatorType),
+			Entry("bitwise not on a float", `~1.5;`, vm.ErrUnexpectedObjectType),
+			Entry("slicing an integer", `5[1:];`, vm.ErrUnexpectedObjectType),
+			Entry("for loop over an integer", `for (x in 5) { x; };`, vm.ErrNotIterable),
+			Entry("array element assignment out of range", `let a = [1]; a[1] = 2;`, vm.ErrIndexOutOfRange),
+			Entry("compound assignment to a missing hash entry", `let h = {}; h["a"] += 1;`, vm.ErrKeyNotFound),
+			Entry("assignment to a string character", `let s = "abc"; s[0] = "x";`, vm.ErrUnexpectedObjectType),
+			Entry("assignment to an unhashable key", `let h = {}; h[[1]] = 1;`, vm.ErrUnhashableType),
+			Entry("slicing with a string index", `[1, 2]["a":];`, vm.ErrUnexpectedObjectType),
+			Entry("slicing with a big integer index", `[1, 2][:int("18446744073709551616")];`, vm.ErrIndexOutOfRange),
+			Entry("big integer index", `[7, 8][int("18446744073709551616")];`, vm.ErrIndexOutOfRange),
+			Entry("big integer string index", `"ab"[int("18446744073709551616")];`, vm.ErrIndexOutOfRange),
+			Entry("division by zero in a callback", `map([1, 0], fn(x) { 1 / x; });`, vm.ErrDivisionByZero),
+		)
+
+		It("func", func() {
+			obj, err := run(`fn(x) { x + 2; };`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.Type()).To(Equal(object.FUNCTION_OBJ))
+		})
+
+		DescribeTable("functions",
+			func(text string, expectedObject object.Object) {
+				obj, err := run(text)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obj).To(Equal(expectedObject))
+			},
+			Entry("let statement evaluates to nil", `let a = 1;`, object.NIL),
+			Entry("empty function body", `fn() {}();`, object.NIL),
+			Entry("local bindings", `let f = fn() { let a = 1; let b = 2; a + b; }; f();`, object.NewInteger(3)),
+			Entry("early return", `let f = fn(x) { if (x > 1) { return 1; }; return 2; }; f(5) + f(0);`, object.NewInteger(3)),
+			Entry("closure", `let adder = fn(x) { fn(y) { x + y; }; }; adder(2)(3);`, object.NewInteger(5)),
+			Entry("nested closure", `let f = fn(a) { fn(b) { fn(c) { a + b + c; }; }; }; f(1)(2)(3);`, object.NewInteger(6)),
+			Entry("global recursion", `let fib = fn(n) { if (n < 2) { return n; }; fib(n - 1) + fib(n - 2); }; fib(15);`, object.NewInteger(610)),
+			Entry("local recursion", `let f = fn() { let count = fn(n) { if (n == 0) { return 0; }; count(n - 1); }; count(3); }; f();`, object.NewInteger(0)),
+			Entry("global defined after the function", `let f = fn() { g(); }; let g = fn() { 5; }; f();`, object.NewInteger(5)),
+			Entry("builtin function shadowed by a global", `let len = fn(x) { 1; }; len("hello");`, object.NewInteger(1)),
+			Entry("builtin calling back a closure", `let k = 10; let f = fn(x) { x * k; }; map([1, 2], f);`, object.NewArray(object.NewInteger(10), object.NewInteger(20))),
+			Entry("nested builtin callbacks", `let f = fn(xs) { reduce(map(xs, fn(x) { x + 1; }), fn(a, b) { a + b; }, 0); }; f([1, 2, 3]) + reduce([[1], [2]], fn(a, b) { a + len(b); }, 0);`, object.NewInteger(11)),
+			Entry("builtin calling back a builtin", `map([[1], [1, 2]], len);`, object.NewArray(object.NewInteger(1), object.NewInteger(2))),
+			Entry("regex replace calling back a closure", `let sep = "+"; let f = fn(m) { repeat(sep, len(m)); }; replace("a-b--c", re("-+"), f);`, object.NewString("a+b++c")),
+		)
+
+		It("wrong number of arguments", func() {
+			_, err := run(`fn(x) { x; }();`)
+			Expect(err).To(MatchError(vm.ErrWrongNumberArguments))
+		})
+
+		It("stack overflow", func() {
+			_, err := run(`let f = fn(x) { f(x); }; f(1);`)
+			Expect(err).To(MatchError(vm.ErrStackOverflow))
+		})
+
+		It("recursion up to the maximum call depth", func() {
+			// f(n) makes n+1 nested calls
+			depth := `let f = fn(n) { if (n == 0) { return 0; }; return 1 + f(n - 1); }; f(%d);`
+
+			obj, err := run(fmt.Sprintf(depth, object.MaxCallDepth-1))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj).To(Equal(object.NewInteger(object.MaxCallDepth - 1)))
+
+			_, err = run(fmt.Sprintf(depth, object.MaxCallDepth))
+			Expect(err).To(MatchError(vm.ErrStackOverflow))
+		})
+
+		It("errors raised by callbacks", func() {
+			_, err := run(`map([1], fn(x, y) { x; });`)
+			Expect(err).To(MatchError(vm.ErrWrongNumberArguments))
+		})
+
+		It("bytecode popping an empty stack", func() {
+			bytecode := &compiler.Bytecode{Instructions: code.Make(code.OpPop)}
+
+			_, err := vm.New(bytecode).Run()
+			Expect(err).To(MatchError(vm.ErrInvalidBytecode))
+		})
+
+		It("print writes to the given output", func() {
+			program, errs := p.ParseProgram(`print("x =", 1); print([2]);`)
+			Expect(errs).To(BeEmpty())
+
+			c := compiler.New()
+			Expect(c.Compile(program)).To(Succeed())
+
+			out := &bytes.Buffer{}
+			_, err := vm.NewWithOutput(c.Bytecode(), vm.NewGlobals(), out).Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out.String()).To(Equal("x = 1\n[2]\n"))
+		})
+
+		It("calling a non function", func() {
+			_, err := run(`5();`)
+			Expect(err).To(MatchError(vm.ErrNotAFunction))
+		})
+	})
+})
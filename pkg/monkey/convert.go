@@ -0,0 +1,166 @@
+package monkey
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+
+	"github.com/aden-q/monkey/internal/object"
+)
+
+// ToObject converts a Go value to a Monkey value: nil to nil, booleans, integers, floats and strings to
+// their Monkey counterparts, slices and arrays to arrays, maps to hashes and *regexp.Regexp to regexes,
+// objects are kept as is
+func ToObject(value any) (Object, error) {
+	switch value := value.(type) {
+	case nil:
+		return NIL, nil
+	case Object:
+		return value, nil
+	case *big.Int:
+		return object.NewBigInteger(new(big.Int).Set(value)), nil
+	case *regexp.Regexp:
+		return &object.Regex{Pattern: value.String(), Regexp: value}, nil
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return object.NewBoolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return object.NewInteger(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.NewBigInteger(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return object.NewFloat(v.Float()), nil
+	case reflect.String:
+		return object.NewString(v.String()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NIL, nil
+		}
+
+		elements := make([]Object, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			element, err := ToObject(v.Index(i).Interface())
+			if err != nil {
+				return NIL, err
+			}
+
+			elements = append(elements, element)
+		}
+
+		return object.NewArray(elements...), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return NIL, nil
+		}
+
+		items := make(map[object.HashKey]Object, v.Len())
+		iter := v.MapRange()
+
+		for iter.Next() {
+			key, err := ToObject(iter.Key().Interface())
+			if err != nil {
+				return NIL, err
+			}
+
+			hashable, ok := key.(object.Hashable)
+			if !ok {
+				return NIL, fmt.Errorf("%w: map key %s", ErrUnsupportedType, iter.Key().Type())
+			}
+
+			item, err := ToObject(iter.Value().Interface())
+			if err != nil {
+				return NIL, err
+			}
+
+			items[hashable.HashKey()] = item
+		}
+
+		return object.NewHash(items), nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return NIL, nil
+		}
+
+		return ToObject(v.Elem().Interface())
+	}
+
+	return NIL, fmt.Errorf("%w: %T", ErrUnsupportedType, value)
+}
+
+// FromObject converts a Monkey value to a Go value: nil to nil, booleans to bool, integers to int64 or
+// *big.Int when they overflow int64, floats to float64, strings to string, arrays to []any and hashes to
+// map[string]any when all their keys are strings, map[any]any otherwise, and regexes to *regexp.Regexp
+func FromObject(obj Object) (any, error) {
+	switch obj := obj.(type) {
+	case *object.Nil:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		if obj.IsBig() {
+			return new(big.Int).Set(obj.Big), nil
+		}
+
+		return obj.Value, nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		elements := make([]any, 0, len(obj.Elements))
+		for _, element := range obj.Elements {
+			value, err := FromObject(element)
+			if err != nil {
+				return nil, err
+			}
+
+			elements = append(elements, value)
+		}
+
+		return elements, nil
+	case *object.Hash:
+		return hashFromObject(obj)
+	case *object.Regex:
+		return obj.Regexp, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, obj.Type())
+}
+
+func hashFromObject(hash *object.Hash) (any, error) {
+	stringKeys := true
+	for hashKey := range hash.Items {
+		stringKeys = stringKeys && hashKey.Type == object.STRING_OBJ
+	}
+
+	items := make(map[any]any, len(hash.Items))
+	for hashKey, item := range hash.Items {
+		key, err := FromObject(hashKey.Object())
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := FromObject(item)
+		if err != nil {
+			return nil, err
+		}
+
+		items[key] = value
+	}
+
+	if !stringKeys {
+		return items, nil
+	}
+
+	stringItems := make(map[string]any, len(items))
+	for key, value := range items {
+		stringItems[key.(string)] = value
+	}
+
+	return stringItems, nil
+}
@@ -0,0 +1,28 @@
+package monkey
+
+import (
+	"errors"
+
+	"github.com/aden-q/monkey/internal/evaluator"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrFuncPanicked    = errors.New("function panicked")
+
+	// errors raised while evaluating programs
+	ErrIdentifierNotFound   = evaluator.ErrIdentifierNotFound
+	ErrNotAFunction         = evaluator.ErrNotAFunction
+	ErrWrongNumberArguments = object.ErrWrongNumberArguments
+)
+
+type (
+	// ParserError is a syntax error, Eval joins the errors found in a source text
+	ParserError = parser.Error
+	// RuntimeError is an error raised while evaluating a program, with the position and stack trace it happened at
+	RuntimeError = evaluator.Error
+	// Exception carries a value thrown by a program and not caught
+	Exception = evaluator.Exception
+)
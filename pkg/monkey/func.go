@@ -0,0 +1,268 @@
+package monkey
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/aden-q/monkey/internal/object"
+)
+
+var (
+	objectType      = reflect.TypeOf((*Object)(nil)).Elem()
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	callContextType = reflect.TypeOf((*CallContext)(nil))
+	bigIntType      = reflect.TypeOf((*big.Int)(nil))
+)
+
+// Func wraps a Go function as a builtin function, e.g. func(string, int) ([]string, error).
+//
+// The function may take a *CallContext first, its other parameters may be booleans, integers, floats,
+// strings, *big.Int, slices and maps of those, Object or any. Arguments are converted to the parameter
+// types, any receives the value converted with FromObject. The function may return nothing, a value
+// converted with ToObject, an error, or a value and an error. A panic of the function is returned
+// to the program as an error wrapping ErrFuncPanicked instead of crashing the host.
+func Func(fn any) (BuiltinFunc, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%w: %T is not a function", ErrUnsupportedType, fn)
+	}
+
+	t := v.Type()
+
+	// the index of the first parameter bound to an argument
+	first := 0
+	if t.NumIn() > 0 && t.In(0) == callContextType {
+		first = 1
+	}
+
+	for i := first; i < t.NumIn(); i++ {
+		param := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			param = param.Elem()
+		}
+
+		if !convertible(param) {
+			return nil, fmt.Errorf("%w: parameter %d of %s", ErrUnsupportedType, i+1, t)
+		}
+	}
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+
+	switch {
+	case t.NumOut() > 2, t.NumOut() == 2 && !returnsError:
+		return nil, fmt.Errorf("%w: results of %s", ErrUnsupportedType, t)
+	}
+
+	// the number of parameters without the variadic one
+	fixed := t.NumIn() - first
+	if t.IsVariadic() {
+		fixed--
+	}
+
+	return func(ctx *CallContext, args ...Object) (Object, error) {
+		if len(args) < fixed || !t.IsVariadic() && len(args) > fixed {
+			return NIL, object.ErrWrongNumberArguments
+		}
+
+		in := make([]reflect.Value, 0, first+len(args))
+		if first == 1 {
+			in = append(in, reflect.ValueOf(ctx))
+		}
+
+		for i, arg := range args {
+			param := t.In(min(first+i, t.NumIn()-1))
+			if first+i >= t.NumIn()-1 && t.IsVariadic() {
+				param = param.Elem()
+			}
+
+			value, err := fromObjectAs(arg, param)
+			if err != nil {
+				return NIL, fmt.Errorf("argument %d: %w", i+1, err)
+			}
+
+			in = append(in, value)
+		}
+
+		out, err := call(v, in)
+		if err != nil {
+			return NIL, err
+		}
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return NIL, err
+			}
+
+			out = out[:len(out)-1]
+		}
+
+		if len(out) == 0 {
+			return NIL, nil
+		}
+
+		return ToObject(out[0].Interface())
+	}, nil
+}
+
+// call calls a wrapped Go function, recovering from its panics
+func call(fn reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrFuncPanicked, r)
+		}
+	}()
+
+	return fn.Call(in), nil
+}
+
+// convertible checks whether arguments can be converted to a parameter type
+func convertible(t reflect.Type) bool {
+	if t == bigIntType || t.Implements(objectType) || t.Kind() == reflect.Interface && objectType.Implements(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return convertible(t.Elem())
+	case reflect.Map:
+		return convertible(t.Key()) && convertible(t.Elem())
+	}
+
+	return false
+}
+
+// fromObjectAs converts a Monkey value to a Go value of the given type
+func fromObjectAs(obj Object, t reflect.Type) (reflect.Value, error) {
+	mismatch := fmt.Errorf("%w: %s is not convertible to %s", object.ErrUnsupportedArgumentType, obj.Type(), t)
+
+	switch {
+	case t.Kind() == reflect.Interface && t.NumMethod() == 0:
+		value, err := FromObject(obj)
+		if err != nil {
+			// values without a Go counterpart, e.g. functions, are passed as is
+			return reflect.ValueOf(&obj).Elem(), nil
+		}
+
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+
+		return reflect.ValueOf(value), nil
+	case t == objectType || t.Kind() == reflect.Interface && reflect.TypeOf(obj).Implements(t):
+		return reflect.ValueOf(obj).Convert(t), nil
+	case reflect.TypeOf(obj) == t:
+		return reflect.ValueOf(obj), nil
+	case t == bigIntType:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		return reflect.ValueOf(new(big.Int).Set(integer.BigInt())), nil
+	}
+
+	value := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Bool:
+		boolean, ok := obj.(*object.Boolean)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		value.SetBool(boolean.Value)
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		value.SetString(str.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		if integer.IsBig() || value.OverflowInt(integer.Value) {
+			return reflect.Value{}, fmt.Errorf("%w: %s overflows %s", object.ErrInvalidArgument, integer.Inspect(), t)
+		}
+
+		value.SetInt(integer.Value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		n := integer.BigInt()
+		if n.Sign() < 0 || !n.IsUint64() || value.OverflowUint(n.Uint64()) {
+			return reflect.Value{}, fmt.Errorf("%w: %s overflows %s", object.ErrInvalidArgument, integer.Inspect(), t)
+		}
+
+		value.SetUint(n.Uint64())
+	case reflect.Float32, reflect.Float64:
+		// integers are promoted the same way they are in arithmetic
+		switch number := obj.(type) {
+		case *object.Float:
+			value.SetFloat(number.Value)
+		case *object.Integer:
+			value.SetFloat(number.Float64())
+		default:
+			return reflect.Value{}, mismatch
+		}
+	case reflect.Slice:
+		if _, ok := obj.(*object.Nil); ok {
+			return value, nil
+		}
+
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		value = reflect.MakeSlice(t, 0, len(array.Elements))
+		for _, element := range array.Elements {
+			elementValue, err := fromObjectAs(element, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			value = reflect.Append(value, elementValue)
+		}
+	case reflect.Map:
+		if _, ok := obj.(*object.Nil); ok {
+			return value, nil
+		}
+
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+
+		value = reflect.MakeMapWithSize(t, len(hash.Items))
+		for hashKey, item := range hash.Items {
+			key, err := fromObjectAs(hashKey.Object(), t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			itemValue, err := fromObjectAs(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			value.SetMapIndex(key, itemValue)
+		}
+	default:
+		return reflect.Value{}, mismatch
+	}
+
+	return value, nil
+}
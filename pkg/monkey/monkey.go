@@ -0,0 +1,161 @@
+// Package monkey embeds the Monkey interpreter in Go programs
+package monkey
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/aden-q/monkey/internal/evaluator"
+	"github.com/aden-q/monkey/internal/lexer"
+	"github.com/aden-q/monkey/internal/object"
+	"github.com/aden-q/monkey/internal/parser"
+)
+
+// the values of Monkey programs
+type (
+	Object      = object.Object
+	Integer     = object.Integer
+	Float       = object.Float
+	Boolean     = object.Boolean
+	String      = object.String
+	Array       = object.Array
+	Hash        = object.Hash
+	Nil         = object.Nil
+	Error       = object.Error
+	Regex       = object.Regex
+	BuiltinFunc = object.BuiltinFunc
+	CallContext = object.CallContext
+)
+
+// the singleton objects
+var (
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
+	NIL   = object.NIL
+)
+
+// interface compliance check
+var _ Interpreter = (*interpreter)(nil)
+
+// Interpreter evaluates Monkey programs, the globals they bind are kept between evaluations,
+// it is not safe for concurrent use
+type Interpreter interface {
+	// Eval parses and evaluates a source text and returns the value of its last statement,
+	// the evaluation stops with the context error once the context is done
+	Eval(ctx context.Context, src string) (Object, error)
+	// SetGlobal binds a global name to a Go value converted with ToObject
+	SetGlobal(name string, value any) error
+	// GetGlobal returns the value bound to a global name
+	GetGlobal(name string) (Object, bool)
+	// Call calls the function bound to a global name with Go values converted with ToObject
+	Call(ctx context.Context, fnName string, args ...any) (Object, error)
+	// RegisterBuiltin adds a builtin function to the builtins of the interpreter
+	RegisterBuiltin(name string, fn BuiltinFunc)
+	// RegisterFunc adds a Go function to the builtins of the interpreter, see Func for the supported functions
+	RegisterFunc(name string, fn any) error
+}
+
+type Config struct {
+	// where builtins such as print write their output, os.Stdout when nil
+	Out io.Writer
+	// the builtin functions available to programs, DefaultBuiltins when nil,
+	// the interpreter works on a copy so that registering builtins never affects other interpreters
+	Builtins map[string]BuiltinFunc
+}
+
+type interpreter struct {
+	parser    parser.Parser
+	env       object.Environment
+	builtins  map[string]BuiltinFunc
+	evaluator evaluator.Evaluator
+}
+
+func New(config Config) Interpreter {
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	builtins := DefaultBuiltins()
+	if config.Builtins != nil {
+		builtins = maps.Clone(config.Builtins)
+	}
+
+	env := object.NewEnvironment()
+
+	return &interpreter{
+		parser:    parser.New(lexer.New()),
+		env:       env,
+		builtins:  builtins,
+		evaluator: evaluator.NewWithBuiltins(env, out, builtins),
+	}
+}
+
+// DefaultBuiltins returns the builtin functions interpreters have by default, e.g. len and print,
+// the map can be filtered or extended and passed as Config.Builtins
+func DefaultBuiltins() map[string]BuiltinFunc {
+	return object.NewBuiltins()
+}
+
+func (i *interpreter) Eval(ctx context.Context, src string) (Object, error) {
+	program, errs := i.parser.ParseProgram(src)
+	if len(errs) != 0 {
+		return NIL, errors.Join(errs...)
+	}
+
+	return i.evaluator.EvalContext(ctx, program)
+}
+
+func (i *interpreter) SetGlobal(name string, value any) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+
+	i.env.Set(name, obj)
+
+	return nil
+}
+
+func (i *interpreter) GetGlobal(name string) (Object, bool) {
+	return i.env.Get(name)
+}
+
+func (i *interpreter) Call(ctx context.Context, fnName string, args ...any) (Object, error) {
+	fn, ok := i.env.Get(fnName)
+	if !ok {
+		if fn, ok = i.builtins[fnName]; !ok {
+			return NIL, ErrIdentifierNotFound
+		}
+	}
+
+	objs := make([]Object, 0, len(args))
+	for _, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return NIL, err
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return i.evaluator.Call(ctx, fn, objs...)
+}
+
+func (i *interpreter) RegisterBuiltin(name string, fn BuiltinFunc) {
+	i.builtins[name] = fn
+}
+
+func (i *interpreter) RegisterFunc(name string, fn any) error {
+	builtin, err := Func(fn)
+	if err != nil {
+		return err
+	}
+
+	i.RegisterBuiltin(name, builtin)
+
+	return nil
+}
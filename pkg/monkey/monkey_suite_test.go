@@ -0,0 +1,13 @@
+package monkey_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMonkey(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Monkey Suite")
+}
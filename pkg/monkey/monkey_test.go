@@ -0,0 +1,325 @@
+package monkey_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aden-q/monkey/pkg/monkey"
+)
+
+var _ = Describe("Monkey", func() {
+	var (
+		ctx    context.Context
+		out    *bytes.Buffer
+		interp monkey.Interpreter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		out = &bytes.Buffer{}
+		interp = monkey.New(monkey.Config{Out: out})
+	})
+
+	Describe("Interpreter", func() {
+		It("keeps globals between evaluations", func() {
+			_, err := interp.Eval(ctx, `let discount = fn(total) { if (total > 100) { total / 10; } else { 0; }; };`)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj, err := interp.Eval(ctx, `discount(250);`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(Equal(monkey.Object(&monkey.Integer{Value: 25})))
+
+			fn, ok := interp.GetGlobal("discount")
+			Expect(ok).To(BeTrue())
+			Expect(fn.Type()).To(BeEquivalentTo("FUNCTION"))
+
+			_, ok = interp.GetGlobal("total")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("sets globals from Go values", func() {
+			Expect(interp.SetGlobal("order", map[string]any{
+				"items": []int{1, 2, 3},
+				"user":  "alice",
+			})).To(Succeed())
+
+			obj, err := interp.Eval(ctx, `"${order["user"]} bought ${len(order["items"])} items";`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("alice bought 3 items"))
+
+			Expect(interp.SetGlobal("ch", make(chan int))).To(MatchError(monkey.ErrUnsupportedType))
+		})
+
+		It("calls functions with Go values", func() {
+			_, err := interp.Eval(ctx, `let add = fn(a, b) { return a + b; };`)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj, err := interp.Call(ctx, "add", 1, 2.5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(Equal(monkey.Object(&monkey.Float{Value: 3.5})))
+
+			obj, err = interp.Call(ctx, "len", "héllo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(Equal(monkey.Object(&monkey.Integer{Value: 5})))
+
+			_, err = interp.Call(ctx, "add", 1)
+			Expect(err).To(MatchError(monkey.ErrWrongNumberArguments))
+
+			_, err = interp.Call(ctx, "missing")
+			Expect(err).To(MatchError(monkey.ErrIdentifierNotFound))
+
+			Expect(interp.SetGlobal("x", 1)).To(Succeed())
+			_, err = interp.Call(ctx, "x")
+			Expect(err).To(MatchError(monkey.ErrNotAFunction))
+		})
+
+		It("has its own builtins", func() {
+			Expect(interp.RegisterFunc("shout", strings.ToUpper)).To(Succeed())
+
+			obj, err := interp.Eval(ctx, `shout("abc");`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("ABC"))
+
+			other := monkey.New(monkey.Config{})
+			_, err = other.Eval(ctx, `shout("abc");`)
+			Expect(err).To(MatchError(monkey.ErrIdentifierNotFound))
+
+			builtins := monkey.DefaultBuiltins()
+			delete(builtins, "print")
+
+			sandboxed := monkey.New(monkey.Config{Builtins: builtins})
+			_, err = sandboxed.Eval(ctx, `print("hi");`)
+			Expect(err).To(MatchError(monkey.ErrIdentifierNotFound))
+
+			obj, err = sandboxed.Call(ctx, "len", []int{1, 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("2"))
+		})
+
+		It("registers builtins", func() {
+			interp.RegisterBuiltin("double", func(_ *monkey.CallContext, args ...monkey.Object) (monkey.Object, error) {
+				value, err := monkey.FromObject(args[0])
+				if err != nil {
+					return monkey.NIL, err
+				}
+
+				return monkey.ToObject(value.(int64) * 2)
+			})
+
+			obj, err := interp.Eval(ctx, `double(21);`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("42"))
+		})
+
+		It("writes output to the configured writer", func() {
+			_, err := interp.Eval(ctx, `print("hello", 1);`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(Equal("hello 1\n"))
+		})
+
+		It("reports parser and runtime errors", func() {
+			_, err := interp.Eval(ctx, "let = 1;\nlet y 2;")
+
+			var parserErr *monkey.ParserError
+			Expect(errors.As(err, &parserErr)).To(BeTrue())
+			Expect(parserErr.Pos.Line).To(Equal(1))
+
+			_, err = interp.Eval(ctx, `{"a": 1}["b"];`)
+
+			var runtimeErr *monkey.RuntimeError
+			Expect(errors.As(err, &runtimeErr)).To(BeTrue())
+
+			_, err = interp.Eval(ctx, `throw "boom";`)
+
+			var exception *monkey.Exception
+			Expect(errors.As(err, &exception)).To(BeTrue())
+			Expect(exception.Value.Inspect()).To(Equal("boom"))
+		})
+
+		It("stops once the context is done", func() {
+			ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, err := interp.Eval(ctx, `while (true) { try { 1; } catch (e) { 2; }; };`)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+
+			_, err = interp.Eval(ctx, `let f = fn(n) { try { f(n + 1); } catch (e) { 0; }; }; f(0);`)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
+	Describe("Func", func() {
+		// call registers a Go function and evaluates a program calling it
+		call := func(fn any, src string) (monkey.Object, error) {
+			Expect(interp.RegisterFunc("f", fn)).To(Succeed())
+			return interp.Eval(ctx, src)
+		}
+
+		It("converts arguments and results", func() {
+			obj, err := call(func(s string, n int) ([]string, error) {
+				return strings.SplitN(s, ",", n), nil
+			}, `f("a,b,c", 2);`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("[a, b,c]"))
+
+			obj, err = call(func(scores map[string]float64, weight float32) float64 {
+				return scores["a"] * float64(weight)
+			}, `f({"a": 3}, 1.5);`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("4.5"))
+
+			obj, err = call(func(n *big.Int, flags []bool, v any) []any {
+				return []any{n.String(), len(flags), v}
+			}, `f(18446744073709551616, [true, false], {"k": [1]});`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("[18446744073709551616, 2, {k: [1]}]"))
+
+			obj, err = call(func(o monkey.Object, s *monkey.String) string {
+				return string(o.Type()) + " " + s.Value
+			}, `f(fn() {}, "x");`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("FUNCTION x"))
+		})
+
+		It("supports variadic functions and the call context", func() {
+			obj, err := call(func(ctx *monkey.CallContext, sep string, parts ...string) {
+				ctx.Out.Write([]byte(strings.Join(parts, sep)))
+			}, `f("-", "a", "b", "c");`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(Equal(monkey.NIL))
+			Expect(out.String()).To(Equal("a-b-c"))
+
+			_, err = call(func(sep string, parts ...string) {}, `f();`)
+			Expect(err).To(MatchError(monkey.ErrWrongNumberArguments))
+		})
+
+		It("checks the number and the types of the arguments", func() {
+			_, err := call(func(a, b int) int { return a + b }, `f(1);`)
+			Expect(err).To(MatchError(monkey.ErrWrongNumberArguments))
+
+			_, err = call(func(a, b int) int { return a + b }, `f(1, "2");`)
+			Expect(err).To(MatchError(ContainSubstring("argument 2: unsupported argument type: STRING is not convertible to int")))
+
+			_, err = call(func(a int8) int8 { return a }, `f(300);`)
+			Expect(err).To(MatchError(ContainSubstring("argument 1: invalid argument: 300 overflows int8")))
+
+			_, err = call(func(a uint) uint { return a }, `f(-1);`)
+			Expect(err).To(MatchError(ContainSubstring("invalid argument")))
+
+			obj, err := call(func(a int) int { return a }, `try { f(1.5); } catch (e) { e["kind"]; };`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("TypeError"))
+		})
+
+		It("returns the errors of the function", func() {
+			boom := errors.New("boom")
+
+			_, err := call(func() error { return boom }, `f();`)
+			Expect(err).To(MatchError(boom))
+
+			obj, err := call(func() (int, error) { return 0, boom }, `try { f(); } catch (e) { e["message"]; };`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("boom"))
+		})
+
+		It("returns the panics of the function as errors", func() {
+			_, err := call(func(xs []int) int { return xs[1] }, `f([1]);`)
+			Expect(err).To(MatchError(monkey.ErrFuncPanicked))
+			Expect(err).To(MatchError(ContainSubstring("index out of range [1] with length 1")))
+
+			obj, err := call(func() { panic("boom") }, `try { f(); } catch (e) { e["message"]; };`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("function panicked: boom"))
+		})
+
+		It("rejects unsupported functions", func() {
+			Expect(interp.RegisterFunc("f", 1)).To(MatchError(monkey.ErrUnsupportedType))
+			Expect(interp.RegisterFunc("f", func(chan int) {})).To(MatchError(monkey.ErrUnsupportedType))
+			Expect(interp.RegisterFunc("f", func() (int, int) { return 0, 0 })).To(MatchError(monkey.ErrUnsupportedType))
+		})
+	})
+
+	Describe("conversions", func() {
+		It("converts Go values to objects and back", func() {
+			huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+			obj, err := monkey.ToObject(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(Equal(monkey.NIL))
+
+			converted, err := monkey.FromObject(obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted).To(BeNil())
+
+			values := []any{
+				true,
+				int64(-3),
+				huge,
+				1.5,
+				"monkey",
+				[]any{int64(1), "a", []any{}},
+				map[string]any{"a": int64(1), "b": []any{false}},
+				map[any]any{int64(1): "one", "two": int64(2)},
+			}
+
+			for _, value := range values {
+				obj, err := monkey.ToObject(value)
+				Expect(err).NotTo(HaveOccurred())
+
+				converted, err := monkey.FromObject(obj)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(converted).To(Equal(value))
+			}
+		})
+
+		It("converts other Go kinds", func() {
+			obj, err := monkey.ToObject(uint64(1) << 63)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("9223372036854775808"))
+
+			name := "alice"
+			obj, err = monkey.ToObject(&name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("alice"))
+
+			obj, err = monkey.ToObject([2]int8{1, 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("[1, 2]"))
+
+			_, err = monkey.ToObject(map[float32]func(){1: nil})
+			Expect(err).To(MatchError(monkey.ErrUnsupportedType))
+		})
+
+		It("converts regexes", func() {
+			pattern := regexp.MustCompile(`(\w+)@(\w+)`)
+			Expect(interp.SetGlobal("email", pattern)).To(Succeed())
+
+			obj, err := interp.Eval(ctx, `captures("mail alice@example now", email)[2];`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.Inspect()).To(Equal("example"))
+
+			obj, err = interp.Eval(ctx, `re("a+", "i");`)
+			Expect(err).NotTo(HaveOccurred())
+
+			converted, err := monkey.FromObject(obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted.(*regexp.Regexp).FindString("xAab")).To(Equal("Aa"))
+		})
+
+		It("rejects functions and unhashable keys", func() {
+			_, err := monkey.ToObject(map[[1]int]int{{1}: 1})
+			Expect(err).To(MatchError(monkey.ErrUnsupportedType))
+
+			_, err = monkey.FromObject(monkey.BuiltinFunc(nil))
+			Expect(err).To(MatchError(monkey.ErrUnsupportedType))
+		})
+	})
+})